@@ -0,0 +1,184 @@
+//go:build release
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/sternelee/openclaw-webhook-bridge/internal/bridge"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/logging"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/webhook"
+)
+
+// bridgeStatusFileName is where cmdRunRelease persists supervisor.Status()
+// under config.Dir(), for cmdStatus (a separate, short-lived process
+// invocation) to read back.
+const bridgeStatusFileName = "bridges-status.json"
+
+// statusTouchInterval is how often runReleaseBridge reports its connection
+// state to the Supervisor and, in turn, how stale the persisted status file
+// can get between writes.
+const statusTouchInterval = 10 * time.Second
+
+// statusPersistInterval is how often startStatusPersister writes the
+// supervisor's current status to disk.
+const statusPersistInterval = 5 * time.Second
+
+// webhookTargetStatusEntry is the on-disk JSON shape of one fan-out
+// target's delivery state, nested under its bridge's bridgeStatusFileEntry.
+type webhookTargetStatusEntry struct {
+	Name         string `json:"name"`
+	LastSuccess  int64  `json:"last_success_ms,omitempty"`
+	LastError    string `json:"last_error,omitempty"`
+	FailureCount int    `json:"failure_count"`
+}
+
+// bridgeStatusFileEntry is the on-disk JSON shape of one bridge.Status entry
+// in bridgeStatusFileName.
+type bridgeStatusFileEntry struct {
+	UID            string                     `json:"uid"`
+	GatewayUp      bool                       `json:"gateway_up"`
+	WebhookUp      bool                       `json:"webhook_up"`
+	LastActivity   int64                      `json:"last_activity_ms"`
+	Restarts       int                        `json:"restarts"`
+	WebhookTargets []webhookTargetStatusEntry `json:"webhook_targets,omitempty"`
+}
+
+// fanoutSenders tracks each running bridge's webhook.FanoutSender by UID, so
+// startStatusPersister can include per-target delivery state in the status
+// file without threading it through bridge.Supervisor (which only knows
+// about gateway/webhook connection state, not fan-out targets).
+var (
+	fanoutSendersMu sync.Mutex
+	fanoutSenders   = make(map[string]*webhook.FanoutSender)
+)
+
+// registerFanoutSender records sender as uid's fan-out sender for the
+// lifetime of the process, so persistBridgeStatus can report its targets'
+// state. Bridges without any webhooks[] targets can skip calling this.
+func registerFanoutSender(uid string, sender *webhook.FanoutSender) {
+	fanoutSendersMu.Lock()
+	defer fanoutSendersMu.Unlock()
+	fanoutSenders[uid] = sender
+}
+
+func webhookTargetStatusFor(uid string) []webhookTargetStatusEntry {
+	fanoutSendersMu.Lock()
+	sender := fanoutSenders[uid]
+	fanoutSendersMu.Unlock()
+	if sender == nil {
+		return nil
+	}
+
+	statuses := sender.Status()
+	entries := make([]webhookTargetStatusEntry, len(statuses))
+	for i, st := range statuses {
+		entries[i] = webhookTargetStatusEntry{
+			Name:         st.Name,
+			LastSuccess:  st.LastSuccess.UnixMilli(),
+			LastError:    st.LastError,
+			FailureCount: st.FailureCount,
+		}
+	}
+	return entries
+}
+
+// startStatusPersister writes supervisor's status to path every
+// statusPersistInterval, and once more just before ctx is done, so "status"
+// invocations never read a file older than the last few seconds of the
+// daemon's life.
+func startStatusPersister(ctx context.Context, path string, supervisor *bridge.Supervisor) {
+	go func() {
+		ticker := time.NewTicker(statusPersistInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				persistBridgeStatus(path, supervisor.Status())
+				return
+			case <-ticker.C:
+				persistBridgeStatus(path, supervisor.Status())
+			}
+		}
+	}()
+}
+
+// persistBridgeStatus writes statuses to path as JSON, logging (not
+// failing) on error since this is best-effort diagnostics.
+func persistBridgeStatus(path string, statuses []bridge.Status) {
+	entries := make([]bridgeStatusFileEntry, len(statuses))
+	for i, st := range statuses {
+		entries[i] = bridgeStatusFileEntry{
+			UID:            st.UID,
+			GatewayUp:      st.GatewayUp,
+			WebhookUp:      st.WebhookUp,
+			LastActivity:   st.LastActivity.UnixMilli(),
+			Restarts:       st.Restarts,
+			WebhookTargets: webhookTargetStatusFor(st.UID),
+		}
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		logging.Printf("[Main] Failed to marshal bridge status: %v", err)
+		return
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		logging.Printf("[Main] Failed to write bridge status file: %v", err)
+	}
+}
+
+// loadBridgeStatus reads back a status file written by persistBridgeStatus.
+func loadBridgeStatus(path string) ([]bridgeStatusFileEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var entries []bridgeStatusFileEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("parse bridge status file: %w", err)
+	}
+	return entries, nil
+}
+
+// printBridgeStatusTable renders entries as a table, restricted to uidFilter
+// when non-empty.
+func printBridgeStatusTable(entries []bridgeStatusFileEntry, uidFilter string) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "UID\tGATEWAY\tWEBHOOK\tLAST ACTIVITY\tRESTARTS")
+	for _, e := range entries {
+		if uidFilter != "" && e.UID != uidFilter {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\n",
+			e.UID,
+			connState(e.GatewayUp),
+			connState(e.WebhookUp),
+			time.UnixMilli(e.LastActivity).Format(time.RFC3339),
+			e.Restarts,
+		)
+		for _, t := range e.WebhookTargets {
+			state := "ok"
+			if t.LastError != "" {
+				state = fmt.Sprintf("error: %s (failures=%d)", t.LastError, t.FailureCount)
+			}
+			fmt.Fprintf(w, "  ↳ %s\t%s\t\t\t\n", t.Name, state)
+		}
+	}
+	w.Flush()
+}
+
+// connState mirrors internal/commands.connectionLabel so the "status" CLI
+// table and the /status chat command agree on terminology.
+func connState(up bool) string {
+	if up {
+		return "connected"
+	}
+	return "disconnected"
+}
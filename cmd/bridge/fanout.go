@@ -0,0 +1,16 @@
+package main
+
+import (
+	"github.com/sternelee/openclaw-webhook-bridge/internal/webhook"
+)
+
+// fanoutTargets builds a webhook.FanoutTarget for each configured target,
+// so cmdRun/runReleaseBridge can wrap their primary webhook.Client with a
+// webhook.FanoutSender in one line.
+func fanoutTargets(targets []webhook.TargetConfig) []*webhook.FanoutTarget {
+	out := make([]*webhook.FanoutTarget, len(targets))
+	for i, t := range targets {
+		out[i] = webhook.NewFanoutTarget(t)
+	}
+	return out
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"log/slog"
+
+	"github.com/sternelee/openclaw-webhook-bridge/internal/config"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/logging"
+)
+
+// setupLogging builds the process-wide slog logger from cfg and installs
+// it as the default via slog.SetDefault, so internal/logging.Printf/
+// Fatalf/Println (and any package that calls slog.Default() directly)
+// route through it. releaseDefaultPath, when non-empty, is used as
+// cfg.LogPath's fallback with a JSON format default - this is what lets
+// cmdRunRelease write real diagnostics to a rotating file instead of the
+// stdout/stderr that cmdStartRelease points at /dev/null.
+func setupLogging(cfg *config.Config, releaseDefaultPath string) {
+	format := cfg.LogFormat
+	path := cfg.LogPath
+	if path == "" && releaseDefaultPath != "" {
+		path = releaseDefaultPath
+		if format == "" {
+			format = "json"
+		}
+	}
+
+	slog.SetDefault(logging.New(logging.Options{
+		Level:      cfg.LogLevel,
+		Format:     format,
+		Path:       path,
+		MaxSizeMB:  cfg.LogMaxSizeMB,
+		MaxBackups: cfg.LogMaxBackups,
+	}))
+}
@@ -19,9 +19,13 @@ import (
 	"github.com/google/uuid"
 	"github.com/skip2/go-qrcode"
 	"github.com/sternelee/openclaw-webhook-bridge/internal/bridge"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/bridge/emitter"
 	"github.com/sternelee/openclaw-webhook-bridge/internal/config"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/logging"
 	"github.com/sternelee/openclaw-webhook-bridge/internal/openclaw"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/pairing"
 	"github.com/sternelee/openclaw-webhook-bridge/internal/sessions"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/shutdown"
 	"github.com/sternelee/openclaw-webhook-bridge/internal/webhook"
 )
 
@@ -36,7 +40,7 @@ func main() {
 		applyConfigArgs(os.Args[2:])
 		cmdStart()
 	case "stop":
-		cmdStop()
+		cmdStop(os.Args[2:])
 	case "status":
 		cmdStatus()
 	case "restart":
@@ -59,8 +63,10 @@ func main() {
 			applyConfigArgs(os.Args[2:])
 		}
 		cmdRun()
+	case "pair":
+		cmdPair(os.Args[2:])
 	default:
-		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\nUsage:\n  openclaw-bridge start [webhook_url=ws://...]\n  openclaw-bridge stop\n  openclaw-bridge status\n  openclaw-bridge restart\n  openclaw-bridge run\n", cmd)
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\nUsage:\n  openclaw-bridge start [webhook_url=ws://...]\n  openclaw-bridge stop [reload]\n  openclaw-bridge status\n  openclaw-bridge restart\n  openclaw-bridge run\n  openclaw-bridge pair list|qr [uid]|revoke <deviceId>\n", cmd)
 		os.Exit(1)
 	}
 }
@@ -86,13 +92,18 @@ func cmdStart() {
 		log.Fatalf("Config error: %v", err)
 	}
 
+	pairingManager, err := pairing.NewManager(dir)
+	if err != nil {
+		log.Fatalf("Failed to load pairing key: %v", err)
+	}
+
 	// Display UID prominently before daemonizing
 	fmt.Println()
 	fmt.Println("╔══════════════════════════════════════════════════════════╗")
 	fmt.Printf("║  %-50s                                         ║\n", config.GetDisplayUID(cfg))
 	fmt.Println("╚══════════════════════════════════════════════════════════╝")
 	fmt.Println()
-	printConnectionQRCode(cfg.WebhookURL, cfg.UID)
+	printConnectionQRCode(pairingManager, cfg.WebhookURL, cfg.UID)
 
 	// Open log file
 	logFile, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
@@ -134,7 +145,7 @@ func cmdStart() {
 	fmt.Printf("Started (PID %d), log: %s\n", pid, logPath)
 }
 
-func cmdStop() {
+func cmdStop(args []string) {
 	dir, err := config.Dir()
 	if err != nil {
 		log.Fatal(err)
@@ -147,6 +158,16 @@ func cmdStop() {
 		os.Exit(1)
 	}
 
+	if len(args) > 0 && args[0] == "reload" {
+		if err := syscall.Kill(pid, syscall.SIGHUP); err != nil {
+			fmt.Println("Not running")
+			os.Remove(pidPath)
+			os.Exit(1)
+		}
+		fmt.Println("Reload signal sent")
+		return
+	}
+
 	if err := stopProcess(pid); err != nil {
 		fmt.Println("Not running")
 		os.Remove(pidPath)
@@ -181,12 +202,21 @@ func cmdStatus() {
 }
 
 func cmdRun() {
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
-	log.Println("[Main] Starting OpenClaw Bridge...")
+	logging.Println("[Main] Starting OpenClaw Bridge...")
 
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("[Main] Failed to load config: %v", err)
+		logging.Fatalf("[Main] Failed to load config: %v", err)
+	}
+	setupLogging(cfg, "")
+
+	dir, err := config.Dir()
+	if err != nil {
+		logging.Fatalf("[Main] Failed to resolve config dir: %v", err)
+	}
+	pairingManager, err := pairing.NewManager(dir)
+	if err != nil {
+		logging.Fatalf("[Main] Failed to load pairing key: %v", err)
 	}
 
 	// ==========================================
@@ -197,23 +227,52 @@ func cmdRun() {
 	fmt.Printf("║  %-50s                                         ║\n", config.GetDisplayUID(cfg))
 	fmt.Println("╚══════════════════════════════════════════════════════════╝")
 	fmt.Println()
-	printConnectionQRCode(cfg.WebhookURL, cfg.UID)
-	log.Printf("[Main] Loaded config: WebhookURL=%s, Gateway=127.0.0.1:%d, AgentID=%s",
+	printConnectionQRCode(pairingManager, cfg.WebhookURL, cfg.UID)
+	logging.Printf("[Main] Loaded config: WebhookURL=%s, Gateway=127.0.0.1:%d, AgentID=%s",
 		cfg.WebhookURL, cfg.OpenClaw.GatewayPort, cfg.OpenClaw.AgentID)
 
 	// Create OpenClaw client
-	clawdbotClient := openclaw.NewClient(
-		cfg.OpenClaw.GatewayPort,
+	clawdbotClient, err := openclaw.NewClient(
+		gatewayURL(cfg.OpenClaw),
 		cfg.OpenClaw.GatewayToken,
 		cfg.OpenClaw.AgentID,
+		openclawTLSConfig(cfg.OpenClaw.TLS),
+		nil,
+		nil,
 	)
+	if err != nil {
+		logging.Fatalf("[Main] Failed to create OpenClaw client: %v", err)
+	}
 
 	// Create session store
-	sessionStore := sessions.NewStore(sessions.DefaultStoreConfig(cfg.SessionStorePath))
-	log.Printf("[Main] Session store configured: %s", cfg.SessionStorePath)
+	storeConfig, err := buildSessionStoreConfig(cfg.SessionStorePath, cfg.SessionStore)
+	if err != nil {
+		logging.Fatalf("[Main] %v", err)
+	}
+	sessionStore := sessions.NewStore(storeConfig)
+	logging.Printf("[Main] Session store configured: driver=%s %s", storeConfig.Driver, cfg.SessionStorePath)
+
+	// Bridges switching away from the file backend migrate any existing
+	// sessions.json into the new backend on first startup.
+	if storeConfig.Driver != sessions.StoreDriverFile {
+		if migrated, err := sessions.MigrateFileStore(cfg.SessionStorePath, sessionStore); err != nil {
+			logging.Printf("[Main] Session store migration from %s failed: %v", cfg.SessionStorePath, err)
+		} else if migrated > 0 {
+			logging.Printf("[Main] Migrated %d session(s) from %s into %s store", migrated, cfg.SessionStorePath, storeConfig.Driver)
+		}
+	}
+
+	// Build the client registry: clawdbotClient under bridge.DefaultTenantID,
+	// plus one additional OpenClaw client per cfg.Tenants entry so this one
+	// bridge process can route several agents through its single webhook
+	// connection instead of requiring a separate process per agent.
+	registry, err := buildClientRegistry(cfg, clawdbotClient)
+	if err != nil {
+		logging.Fatalf("[Main] %v", err)
+	}
 
 	// Create bridge
-	bridgeInstance := bridge.NewBridge(nil, clawdbotClient)
+	bridgeInstance := bridge.NewMultiTenantBridge(nil, registry, bridge.DefaultTenantResolver{})
 	bridgeInstance.SetUID(cfg.UID)               // Set UID for message routing
 	bridgeInstance.SetSessionStore(sessionStore) // Configure session store
 
@@ -229,15 +288,42 @@ func cmdRun() {
 	}
 	bridgeInstance.SetSessionScope(scope)
 
-	// Set OpenClaw event callback to forward to webhook
-	clawdbotClient.SetEventCallback(bridgeInstance.HandleOpenClawEvent)
+	// Configure idempotency dedup for retried webhook deliveries
+	idemConfig := &sessions.IdempotencyConfig{
+		TTL:        time.Duration(cfg.IdempotencyTTLSeconds) * time.Second,
+		MaxEntries: cfg.IdempotencyMaxEntries,
+	}
+	bridgeInstance.SetIdempotencyStore(sessions.NewIdempotencyStore(idemConfig))
+
+	// Configure the session reset matcher from bridge.json, falling back to
+	// the "/new" / "/reset" exact-match default when unset
+	if len(cfg.ResetTriggers) > 0 || len(cfg.ResetTriggerRegexes) > 0 {
+		matcher, err := sessions.NewPhraseMatcher(sessions.ResetTriggerConfig{
+			Phrases:    cfg.ResetTriggers,
+			Regexes:    cfg.ResetTriggerRegexes,
+			PrefixOnly: cfg.ResetTriggerPrefix,
+		})
+		if err != nil {
+			logging.Fatalf("[Main] Invalid reset trigger config: %v", err)
+		}
+		bridgeInstance.SetResetMatcher(matcher)
+	}
+
+	// Set OpenClaw event callbacks to forward every tenant's events to webhook
+	wireTenantEventCallbacks(registry, bridgeInstance)
 
 	// Create webhook client with bridge message handler
-	webhookClient := webhook.NewClient(
+	webhookClient, err := webhook.NewClient(
 		cfg.WebhookURL,
 		bridgeInstance.HandleWebhookMessage,
 		cfg.UID, // Pass UID for message identification
+		webhookTLSConfig(cfg.WebhookTLS),
+		nil,
+		nil,
 	)
+	if err != nil {
+		logging.Fatalf("[Main] Failed to create webhook client: %v", err)
+	}
 
 	// Set webhook client on bridge
 	bridgeInstance.SetWebhookClient(webhookClient)
@@ -245,41 +331,78 @@ func cmdRun() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Start OpenClaw persistent connection
-	log.Println("[Main] Connecting to OpenClaw Gateway...")
-	if err := clawdbotClient.Connect(ctx); err != nil {
-		log.Fatalf("[Main] Failed to connect to OpenClaw Gateway: %v", err)
+	// Keep metrics.SessionActive current from the backend's own change feed
+	// instead of polling, when the configured driver supports it (file,
+	// redis, etcd - not bbolt).
+	if err := bridgeInstance.WatchSessionChanges(ctx); err != nil {
+		logging.Printf("[Main] Session store does not support watching for changes, falling back to polling: %v", err)
+	}
+
+	// rc holds the clients reloadConfig may swap out on SIGHUP, so every
+	// reader below (the shutdown coordinator, the /status provider) always
+	// acts on the current ones instead of whatever was live at startup.
+	rc := &runtimeClients{webhookClient: webhookClient, clawdbotClient: clawdbotClient}
+	watchConfigDir(ctx, dir, func() { reloadConfig(ctx, cfg, bridgeInstance, rc) })
+
+	startMetricsServer(ctx, cfg, singleBridgeStatusProvider(cfg, rc, sessionStore))
+	startPairingServer(ctx, cfg, pairingManager)
+
+	// Durably buffer OpenClaw events so a transient webhook outage doesn't
+	// drop them mid-conversation, fanning each one out to every additional
+	// downstream sink in cfg.WebhookTargets alongside the primary channel.
+	fanoutSender := webhook.NewFanoutSender(webhookClient, fanoutTargets(cfg.WebhookTargets))
+	rc.fanoutSender = fanoutSender
+	emitterDir := filepath.Join(filepath.Dir(cfg.SessionStorePath), "emitter-queue")
+	eventEmitter, err := emitter.NewBufferedEmitter(fanoutSender, emitter.DefaultConfig(emitterDir))
+	if err != nil {
+		logging.Fatalf("[Main] Failed to create event emitter: %v", err)
+	}
+	eventEmitter.Start(ctx)
+	bridgeInstance.SetEmitter(eventEmitter)
+
+	// Start OpenClaw persistent connection(s)
+	logging.Println("[Main] Connecting to OpenClaw Gateway...")
+	if err := connectRegistryClients(ctx, registry); err != nil {
+		logging.Fatalf("[Main] Failed to connect to OpenClaw Gateway: %v", err)
 	}
-	defer clawdbotClient.Close()
 
 	// Start Webhook persistent connection
-	log.Println("[Main] Connecting to Webhook server...")
+	logging.Println("[Main] Connecting to Webhook server...")
 	if err := webhookClient.Connect(ctx); err != nil {
-		log.Fatalf("[Main] Failed to connect to Webhook server: %v", err)
+		logging.Fatalf("[Main] Failed to connect to Webhook server: %v", err)
 	}
-	defer webhookClient.Close()
 
-	// Make sure to close connections on shutdown
-	go func() {
-		<-ctx.Done()
-		log.Println("[Main] Shutting down connections...")
-		webhookClient.Close()
-		clawdbotClient.Close()
-	}()
+	// Closers run in this order on shutdown so a stuck webhook socket
+	// can't block the session store from flushing under it, and a closer
+	// that hangs past its timeout forces an exit instead of leaving the
+	// process stuck - see internal/shutdown. They read through rc, so
+	// they always close whatever client is current even after a reload.
+	coord := shutdown.New()
+	coord.Register("emitter", 0, 5*time.Second, func(context.Context) error { return eventEmitter.Close() })
+	coord.Register("webhook client", 1, 5*time.Second, func(context.Context) error { return rc.webhookClient.Close() })
+	coord.Register("openclaw client", 1, 5*time.Second, func(context.Context) error { closeRegistryClients(registry); return nil })
+	coord.Register("session store", 10, 5*time.Second, func(context.Context) error { sessionStore.Close(); return nil })
 
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
 
-	log.Println("[Main] OpenClaw Bridge started successfully")
-	log.Println("[Main] Press Ctrl+C to stop")
+	logging.Println("[Main] OpenClaw Bridge started successfully")
+	logging.Println("[Main] Press Ctrl+C to stop")
 
-	select {
-	case <-sigChan:
-		log.Println("[Main] Received shutdown signal, stopping...")
-		cancel()
+	for sig := range sigChan {
+		if sig == syscall.SIGHUP {
+			logging.Println("[Main] Received SIGHUP, reloading config...")
+			reloadConfig(ctx, cfg, bridgeInstance, rc)
+			continue
+		}
+		logging.Println("[Main] Received shutdown signal, stopping...")
+		break
 	}
 
-	log.Println("[Main] OpenClaw Bridge stopped")
+	cancel()
+	coord.Shutdown(context.Background())
+
+	logging.Println("[Main] OpenClaw Bridge stopped")
 }
 
 func isRunning(pidPath string) bool {
@@ -380,24 +503,22 @@ func generateUID() string {
 	return uuid.NewString()
 }
 
-func printConnectionQRCode(webhookURL, uid string) {
+// printConnectionQRCode mints a short-lived enrollment token via manager
+// (see internal/pairing) and renders it as a QR code. The token, not the
+// raw webhookURL/uid, is what a photograph of the terminal exposes - it
+// expires in pairing.DefaultTokenTTL and can only be redeemed once.
+func printConnectionQRCode(manager *pairing.Manager, webhookURL, uid string) {
 	if webhookURL == "" || uid == "" {
 		return
 	}
 
-	payloadBytes, err := json.Marshal(map[string]string{
-		"wsUrl": webhookURL,
-		"uid":   uid,
-	})
+	token, err := manager.MintToken(webhookURL, uid, pairing.DefaultTokenTTL)
 	if err != nil {
-		log.Printf("[Main] Failed to build QR payload: %v", err)
+		log.Printf("[Main] Failed to mint pairing token: %v", err)
 		return
 	}
-	payload := string(payloadBytes)
 
-	fmt.Println("Scan this QR with openclaw-mapp to connect:")
-	renderQRCode(payload)
-	fmt.Printf("QR payload: %s\n\n", payload)
+	renderPairingQR(token)
 }
 
 func renderQRCode(payload string) {
@@ -8,6 +8,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
@@ -19,9 +20,13 @@ import (
 	"github.com/google/uuid"
 	"github.com/skip2/go-qrcode"
 	"github.com/sternelee/openclaw-webhook-bridge/internal/bridge"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/bridge/emitter"
 	"github.com/sternelee/openclaw-webhook-bridge/internal/config"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/logging"
 	"github.com/sternelee/openclaw-webhook-bridge/internal/openclaw"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/pairing"
 	"github.com/sternelee/openclaw-webhook-bridge/internal/sessions"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/shutdown"
 	"github.com/sternelee/openclaw-webhook-bridge/internal/webhook"
 )
 
@@ -36,9 +41,9 @@ func main() {
 		applyConfigArgs(os.Args[2:])
 		cmdStartRelease()
 	case "stop":
-		cmdStop()
+		cmdStop(os.Args[2:])
 	case "status":
-		cmdStatus()
+		cmdStatus(os.Args[2:])
 	case "restart":
 		applyConfigArgs(os.Args[2:])
 		dir, _ := config.Dir()
@@ -59,8 +64,10 @@ func main() {
 			applyConfigArgs(os.Args[2:])
 		}
 		cmdRunRelease()
+	case "pair":
+		cmdPair(os.Args[2:])
 	default:
-		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\nUsage:\n  openclaw-bridge start [webhook_url=ws://...]\n  openclaw-bridge stop\n  openclaw-bridge status\n  openclaw-bridge restart\n  openclaw-bridge run\n", cmd)
+		fmt.Fprintf(os.Stderr, "Unknown command: %s\n\nUsage:\n  openclaw-bridge start [webhook_url=ws://...]\n  openclaw-bridge stop [reload]\n  openclaw-bridge status\n  openclaw-bridge restart\n  openclaw-bridge run\n  openclaw-bridge pair list|qr [uid]|revoke <deviceId>\n", cmd)
 		os.Exit(1)
 	}
 }
@@ -86,13 +93,18 @@ func cmdStartRelease() {
 		log.Fatalf("Config error: %v", err)
 	}
 
+	pairingManager, err := pairing.NewManager(dir)
+	if err != nil {
+		log.Fatalf("Failed to load pairing key: %v", err)
+	}
+
 	// Display UID prominently before daemonizing
 	fmt.Println()
 	fmt.Println("╔══════════════════════════════════════════════════════════╗")
 	fmt.Printf("║  %-50s                                         ║\n", config.GetDisplayUID(cfg))
 	fmt.Println("╚══════════════════════════════════════════════════════════╝")
 	fmt.Println()
-	printConnectionQRCode(cfg.WebhookURL, cfg.UID)
+	printConnectionQRCode(pairingManager, cfg.WebhookURL, cfg.UID)
 
 	// Open /dev/null for both stdout and stderr (no logging in release mode)
 	devNull, err := os.Open(os.DevNull)
@@ -127,103 +139,231 @@ func cmdStartRelease() {
 	fmt.Printf("Started (PID %d), logging disabled in release mode\n", pid)
 }
 
-// cmdRunRelease runs the bridge without any logging (release build)
+// cmdRunRelease runs every bridge in cfg.BridgeConfigs() as a daemon, one
+// goroutine each under a bridge.Supervisor that restarts an individual
+// bridge on panic or connection failure without taking the others - or the
+// process - down. Diagnostics go to a rotating file under config.Dir() by
+// default (see setupLogging) instead of the /dev/null cmdStartRelease wires
+// up for stdout/stderr. The PID file cmdStartRelease writes tracks this
+// single supervisor process, not any one bridge.
 func cmdRunRelease() {
-	// Disable all logging in release mode
-	log.SetOutput(os.Stderr)
-	log.SetFlags(0)
-
 	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("[Main] Failed to load config: %v", err)
+		logging.Fatalf("[Main] Failed to load config: %v", err)
+	}
+	dir, err := config.Dir()
+	if err != nil {
+		logging.Fatalf("[Main] Failed to resolve config dir: %v", err)
+	}
+	setupLogging(cfg, filepath.Join(dir, "bridge.log"))
+
+	pairingManager, err := pairing.NewManager(dir)
+	if err != nil {
+		logging.Fatalf("[Main] Failed to load pairing key: %v", err)
 	}
 
+	bridgeConfigs := cfg.BridgeConfigs()
+
 	// ==========================================
-	// DISPLAY BRIDGE UID (prominently)
+	// DISPLAY BRIDGE UID(s) (prominently)
 	// ==========================================
 	fmt.Println()
 	fmt.Println("╔══════════════════════════════════════════════════════════╗")
 	fmt.Printf("║  %-50s                                         ║\n", config.GetDisplayUID(cfg))
 	fmt.Println("╚══════════════════════════════════════════════════════════╝")
 	fmt.Println()
-	printConnectionQRCode(cfg.WebhookURL, cfg.UID)
+	for _, bc := range bridgeConfigs {
+		printConnectionQRCode(pairingManager, bc.WebhookURL, bc.UID)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	supervisor := bridge.NewSupervisor()
+	metricsServer := startMetricsServer(ctx, cfg, func() any { return supervisor.Status() })
+	pairingServer := startPairingServer(ctx, cfg, pairingManager)
+
+	for _, bc := range bridgeConfigs {
+		bc := bc
+		go supervisor.Run(ctx, bc.UID, func(ctx context.Context) error {
+			return runReleaseBridge(ctx, cfg, bc, supervisor)
+		})
+	}
+	startStatusPersister(ctx, filepath.Join(dir, bridgeStatusFileName), supervisor)
+
+	// Each bridge goroutine closes its own clients via defer as
+	// runReleaseBridge returns on ctx cancellation; the coordinator here
+	// only owns the process-wide metrics/pairing servers, which is enough
+	// to bound how long a SIGTERM/SIGINT can hang before forcing an exit.
+	coord := shutdown.New()
+	coord.Register("metrics server", 0, 5*time.Second, closeServer(metricsServer))
+	coord.Register("pairing server", 0, 5*time.Second, closeServer(pairingServer))
 
-	// Create OpenClaw client
-	clawdbotClient := openclaw.NewClient(
-		cfg.OpenClaw.GatewayPort,
-		cfg.OpenClaw.GatewayToken,
-		cfg.OpenClaw.AgentID,
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
+
+	logging.Println("[Main] OpenClaw Bridge started successfully")
+	logging.Println("[Main] Press Ctrl+C to stop")
+
+	for sig := range sigChan {
+		if sig == syscall.SIGHUP {
+			logging.Println("[Main] Received SIGHUP, reload not yet supported - ignoring")
+			continue
+		}
+		logging.Println("[Main] Received shutdown signal, stopping...")
+		break
+	}
+
+	cancel()
+	coord.Shutdown(context.Background())
+
+	logging.Println("[Main] OpenClaw Bridge stopped")
+}
+
+// closeServer adapts an *http.Server (possibly nil, when its port isn't
+// configured) to a shutdown.Coordinator closer.
+func closeServer(server *http.Server) func(ctx context.Context) error {
+	return func(context.Context) error {
+		if server == nil {
+			return nil
+		}
+		return server.Close()
+	}
+}
+
+// runReleaseBridge builds and runs a single bridge from bc until ctx is
+// cancelled or one of its connections fails, reporting connection state to
+// supervisor every statusTouchInterval so the "status" CLI table stays
+// current. A returned error tells the caller's bridge.Supervisor to restart
+// this bridge; ctx cancellation returns nil.
+func runReleaseBridge(ctx context.Context, cfg *config.Config, bc config.BridgeConfig, supervisor *bridge.Supervisor) error {
+	clawdbotClient, err := openclaw.NewClient(
+		gatewayURL(bc.OpenClaw),
+		bc.OpenClaw.GatewayToken,
+		bc.OpenClaw.AgentID,
+		openclawTLSConfig(bc.OpenClaw.TLS),
+		nil,
+		nil,
 	)
+	if err != nil {
+		return fmt.Errorf("create OpenClaw client: %w", err)
+	}
+
+	storeConfig, err := buildSessionStoreConfig(bc.SessionStorePath, bc.SessionStore)
+	if err != nil {
+		return fmt.Errorf("session store: %w", err)
+	}
+	sessionStore := sessions.NewStore(storeConfig)
+
+	// Bridges switching away from the file backend migrate any existing
+	// sessions.json into the new backend on first startup.
+	if storeConfig.Driver != sessions.StoreDriverFile {
+		if migrated, err := sessions.MigrateFileStore(bc.SessionStorePath, sessionStore); err != nil {
+			logging.Printf("[Main] Session store migration from %s failed: %v", bc.SessionStorePath, err)
+		} else if migrated > 0 {
+			logging.Printf("[Main] Migrated %d session(s) from %s into %s store", migrated, bc.SessionStorePath, storeConfig.Driver)
+		}
+	}
+
+	// Only the top-level bridge entry (bc.UID == cfg.UID) routes cfg.Tenants;
+	// additional Bridges entries stay single-tenant.
+	registry := bridge.NewSingleClientRegistry(clawdbotClient)
+	if bc.UID == cfg.UID {
+		var err error
+		if registry, err = buildClientRegistry(cfg, clawdbotClient); err != nil {
+			return fmt.Errorf("build client registry: %w", err)
+		}
+	}
 
-	// Create session store
-	sessionStore := sessions.NewStore(sessions.DefaultStoreConfig(cfg.SessionStorePath))
+	bridgeInstance := bridge.NewMultiTenantBridge(nil, registry, bridge.DefaultTenantResolver{})
+	bridgeInstance.SetUID(bc.UID)
+	bridgeInstance.SetSessionStore(sessionStore)
 
-	// Create bridge
-	bridgeInstance := bridge.NewBridge(nil, clawdbotClient)
-	bridgeInstance.SetUID(cfg.UID)               // Set UID for message routing
-	bridgeInstance.SetSessionStore(sessionStore) // Configure session store
+	// Keep metrics.SessionActive current from the backend's own change feed
+	// instead of polling, when the configured driver supports it (file,
+	// redis, etcd - not bbolt).
+	if err := bridgeInstance.WatchSessionChanges(ctx); err != nil {
+		logging.Printf("[Main] Session store does not support watching for changes, falling back to polling: %v", err)
+	}
 
-	// Set session scope from config
 	var scope sessions.SessionScope
-	switch cfg.SessionScope {
+	switch bc.SessionScope {
 	case "global":
 		scope = sessions.SessionScopeGlobal
-	case "per-sender":
-		fallthrough
 	default:
 		scope = sessions.SessionScopePerSender
 	}
 	bridgeInstance.SetSessionScope(scope)
 
-	// Set OpenClaw event callback to forward to webhook
-	clawdbotClient.SetEventCallback(bridgeInstance.HandleOpenClawEvent)
+	idemConfig := &sessions.IdempotencyConfig{
+		TTL:        time.Duration(cfg.IdempotencyTTLSeconds) * time.Second,
+		MaxEntries: cfg.IdempotencyMaxEntries,
+	}
+	bridgeInstance.SetIdempotencyStore(sessions.NewIdempotencyStore(idemConfig))
+
+	if len(cfg.ResetTriggers) > 0 || len(cfg.ResetTriggerRegexes) > 0 {
+		matcher, err := sessions.NewPhraseMatcher(sessions.ResetTriggerConfig{
+			Phrases:    cfg.ResetTriggers,
+			Regexes:    cfg.ResetTriggerRegexes,
+			PrefixOnly: cfg.ResetTriggerPrefix,
+		})
+		if err != nil {
+			return fmt.Errorf("invalid reset trigger config: %w", err)
+		}
+		bridgeInstance.SetResetMatcher(matcher)
+	}
 
-	// Create webhook client with bridge message handler
-	webhookClient := webhook.NewClient(
-		cfg.WebhookURL,
+	wireTenantEventCallbacks(registry, bridgeInstance)
+
+	webhookClient, err := webhook.NewClient(
+		bc.WebhookURL,
 		bridgeInstance.HandleWebhookMessage,
-		cfg.UID, // Pass UID for message identification
+		bc.UID,
+		webhookTLSConfig(bc.WebhookTLS),
+		nil,
+		nil,
 	)
-
-	// Set webhook client on bridge
+	if err != nil {
+		return fmt.Errorf("create webhook client: %w", err)
+	}
 	bridgeInstance.SetWebhookClient(webhookClient)
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	fanoutSender := webhook.NewFanoutSender(webhookClient, fanoutTargets(cfg.WebhookTargets))
+	registerFanoutSender(bc.UID, fanoutSender)
+	emitterDir := filepath.Join(filepath.Dir(bc.SessionStorePath), "emitter-queue-"+bc.UID)
+	eventEmitter, err := emitter.NewBufferedEmitter(fanoutSender, emitter.DefaultConfig(emitterDir))
+	if err != nil {
+		return fmt.Errorf("create event emitter: %w", err)
+	}
+	eventEmitter.Start(ctx)
+	defer eventEmitter.Close()
+	bridgeInstance.SetEmitter(eventEmitter)
 
-	// Start OpenClaw persistent connection
-	if err := clawdbotClient.Connect(ctx); err != nil {
-		log.Fatalf("[Main] Failed to connect to OpenClaw Gateway: %v", err)
+	if err := connectRegistryClients(ctx, registry); err != nil {
+		return fmt.Errorf("connect to OpenClaw Gateway: %w", err)
 	}
-	defer clawdbotClient.Close()
+	defer closeRegistryClients(registry)
 
-	// Start Webhook persistent connection
 	if err := webhookClient.Connect(ctx); err != nil {
-		log.Fatalf("[Main] Failed to connect to Webhook server: %v", err)
+		return fmt.Errorf("connect to Webhook server: %w", err)
 	}
 	defer webhookClient.Close()
 
-	// Make sure to close connections on shutdown
-	go func() {
-		<-ctx.Done()
-		webhookClient.Close()
-		clawdbotClient.Close()
-	}()
-
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
-
-	fmt.Println("OpenClaw Bridge started successfully (release mode - no logging)")
-	fmt.Println("Press Ctrl+C to stop")
+	statusTicker := time.NewTicker(statusTouchInterval)
+	defer statusTicker.Stop()
+	supervisor.Touch(bc.UID, clawdbotClient.IsConnected(), webhookClient.IsConnected())
 
-	select {
-	case <-sigChan:
-		fmt.Println("\nShutting down...")
-		cancel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-statusTicker.C:
+			supervisor.Touch(bc.UID, clawdbotClient.IsConnected(), webhookClient.IsConnected())
+		}
 	}
 }
 
-func cmdStop() {
+func cmdStop(args []string) {
 	dir, err := config.Dir()
 	if err != nil {
 		log.Fatal(err)
@@ -236,6 +376,16 @@ func cmdStop() {
 		os.Exit(1)
 	}
 
+	if len(args) > 0 && args[0] == "reload" {
+		if err := syscall.Kill(pid, syscall.SIGHUP); err != nil {
+			fmt.Println("Not running")
+			os.Remove(pidPath)
+			os.Exit(1)
+		}
+		fmt.Println("Reload signal sent")
+		return
+	}
+
 	if err := stopProcess(pid); err != nil {
 		fmt.Println("Not running")
 		os.Remove(pidPath)
@@ -253,20 +403,45 @@ func cmdStop() {
 	fmt.Println("Stopped")
 }
 
-func cmdStatus() {
+// cmdStatus reports whether the daemon is running and, if it has persisted a
+// bridges-status.json (see startStatusPersister), prints a per-bridge table.
+// args accepts an optional "--uid=<uid>" filter; the leading "--" is
+// stripped before reuse of the key=value parseKeyValue convention so the
+// flag-style syntax still works.
+func cmdStatus(args []string) {
 	dir, err := config.Dir()
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	pidPath := filepath.Join(dir, "bridge.pid")
-	if isRunning(pidPath) {
-		pid, _ := readPID(pidPath)
-		fmt.Printf("Running (PID %d)\n", pid)
-	} else {
+	if !isRunning(pidPath) {
 		fmt.Println("Not running")
 		os.Exit(1)
 	}
+
+	pid, _ := readPID(pidPath)
+	fmt.Printf("Running (PID %d)\n", pid)
+
+	entries, err := loadBridgeStatus(filepath.Join(dir, bridgeStatusFileName))
+	if err != nil {
+		// No status file yet (e.g. daemon just started, or a dev build that
+		// never ran "run"). The PID check above is all we can report.
+		return
+	}
+
+	uidFilter := parseKeyValue(stripFlagPrefixes(args))["uid"]
+	printBridgeStatusTable(entries, uidFilter)
+}
+
+// stripFlagPrefixes removes a leading "--" from each arg (e.g. "--uid=x" ->
+// "uid=x") so flag-style arguments can be parsed with parseKeyValue.
+func stripFlagPrefixes(args []string) []string {
+	out := make([]string, len(args))
+	for i, a := range args {
+		out[i] = strings.TrimPrefix(a, "--")
+	}
+	return out
 }
 
 // Helper functions
@@ -367,24 +542,22 @@ func generateUID() string {
 	return uuid.NewString()
 }
 
-func printConnectionQRCode(webhookURL, uid string) {
+// printConnectionQRCode mints a short-lived enrollment token via manager
+// (see internal/pairing) and renders it as a QR code. The token, not the
+// raw webhookURL/uid, is what a photograph of the terminal exposes - it
+// expires in pairing.DefaultTokenTTL and can only be redeemed once.
+func printConnectionQRCode(manager *pairing.Manager, webhookURL, uid string) {
 	if webhookURL == "" || uid == "" {
 		return
 	}
 
-	payloadBytes, err := json.Marshal(map[string]string{
-		"wsUrl": webhookURL,
-		"uid":   uid,
-	})
+	token, err := manager.MintToken(webhookURL, uid, pairing.DefaultTokenTTL)
 	if err != nil {
-		log.Printf("[Main] Failed to build QR payload: %v", err)
+		log.Printf("[Main] Failed to mint pairing token: %v", err)
 		return
 	}
-	payload := string(payloadBytes)
 
-	fmt.Println("Scan this QR with openclaw-mapp to connect:")
-	renderQRCode(payload)
-	fmt.Printf("QR payload: %s\n\n", payload)
+	renderPairingQR(token)
 }
 
 func renderQRCode(payload string) {
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/sternelee/openclaw-webhook-bridge/internal/config"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/logging"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/metrics"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/sessions"
+)
+
+// startMetricsServer starts the Prometheus /metrics and JSON /status
+// endpoints when cfg.MetricsPort is configured, and returns the server so
+// the caller can shut it down alongside the rest of the bridge. Returns nil
+// if metrics are disabled (the default). status may be nil to omit /status.
+func startMetricsServer(ctx context.Context, cfg *config.Config, status metrics.StatusProvider) *http.Server {
+	if cfg.MetricsPort == 0 {
+		return nil
+	}
+
+	addr := fmt.Sprintf("127.0.0.1:%d", cfg.MetricsPort)
+	server := metrics.NewServer(addr, cfg.MetricsToken, status)
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logging.Printf("[Main] Metrics server error: %v", err)
+		}
+	}()
+	logging.Printf("[Main] Metrics server listening on %s", addr)
+
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	return server
+}
+
+// singleBridgeStatus is the /status JSON shape for a non-release (single
+// bridge) run, where there's no bridge.Supervisor to ask for a snapshot.
+type singleBridgeStatus struct {
+	UID          string `json:"uid"`
+	GatewayUp    bool   `json:"gateway_up"`
+	WebhookUp    bool   `json:"webhook_up"`
+	SessionCount int    `json:"session_count"`
+}
+
+// singleBridgeStatusProvider builds a metrics.StatusProvider reporting
+// rc's live client state and store's session count under cfg.UID, for
+// cmdRun's single-bridge /status. cfg and rc are read fresh on every call,
+// so a reload (see reloadConfig) is reflected without re-registering.
+func singleBridgeStatusProvider(cfg *config.Config, rc *runtimeClients, store *sessions.Store) metrics.StatusProvider {
+	return func() any {
+		sessionCount := 0
+		if snapshot, err := store.LoadReadonly(); err == nil {
+			sessionCount = snapshot.Count()
+		}
+		return singleBridgeStatus{
+			UID:          cfg.UID,
+			GatewayUp:    rc.clawdbotClient.IsConnected(),
+			WebhookUp:    rc.webhookClient.IsConnected(),
+			SessionCount: sessionCount,
+		}
+	}
+}
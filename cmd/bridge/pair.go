@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/sternelee/openclaw-webhook-bridge/internal/config"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/logging"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/pairing"
+)
+
+// startPairingServer starts the POST /pair/redeem enrollment endpoint when
+// cfg.PairingPort is configured, and returns the server so the caller can
+// shut it down alongside the rest of the bridge. Returns nil if pairing is
+// disabled (the default). It binds to cfg.PairingBindAddr, not just
+// loopback: a mapp device redeeming a pairing token is, by design, a
+// different host than the bridge (the token no longer carries the bridge's
+// own address for the device to fall back on), so this endpoint has to be
+// reachable from wherever that device actually is.
+func startPairingServer(ctx context.Context, cfg *config.Config, manager *pairing.Manager) *http.Server {
+	if cfg.PairingPort == 0 {
+		return nil
+	}
+
+	bindAddr := cfg.PairingBindAddr
+	if bindAddr == "" {
+		bindAddr = "127.0.0.1"
+	}
+	addr := fmt.Sprintf("%s:%d", bindAddr, cfg.PairingPort)
+	mux := http.NewServeMux()
+	mux.Handle("/pair/redeem", manager.RedeemHandler())
+	mux.Handle("/pair/mint", manager.MintHandler())
+	server := &http.Server{Addr: addr, Handler: mux}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logging.Printf("[Main] Pairing server error: %v", err)
+		}
+	}()
+	logging.Printf("[Main] Pairing server listening on %s", addr)
+
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	return server
+}
+
+const pairUsage = "Usage: openclaw-bridge pair list|qr [uid]|revoke <deviceId>"
+
+// cmdPair implements "openclaw-bridge pair list|qr [uid]|revoke <deviceId>",
+// letting an operator audit paired mapp clients, print a fresh pairing QR
+// without restarting the daemon, and revoke a device's credential.
+func cmdPair(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, pairUsage)
+		os.Exit(1)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+	dir, err := config.Dir()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to resolve config dir: %v\n", err)
+		os.Exit(1)
+	}
+	manager, err := pairing.NewManager(dir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load pairing state: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "qr":
+		cmdPairQR(cfg, manager, args[1:])
+	case "list":
+		devices, err := manager.Devices().List()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to list devices: %v\n", err)
+			os.Exit(1)
+		}
+		if len(devices) == 0 {
+			fmt.Println("No paired devices")
+			return
+		}
+		for _, d := range devices {
+			status := "active"
+			if d.RevokedAt != nil {
+				status = "revoked"
+			}
+			fmt.Printf("%s\tuid=%s\tpaired=%s\tstatus=%s\n", d.ID, d.UID, d.PairedAt.Format("2006-01-02T15:04:05Z07:00"), status)
+		}
+	case "revoke":
+		if len(args) < 2 {
+			fmt.Fprintln(os.Stderr, "Usage: openclaw-bridge pair revoke <deviceId>")
+			os.Exit(1)
+		}
+		if err := manager.Devices().Revoke(args[1]); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to revoke device: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Revoked %s\n", args[1])
+	default:
+		fmt.Fprintln(os.Stderr, pairUsage)
+		os.Exit(1)
+	}
+}
+
+// cmdPairQR prints a fresh pairing QR for one bridge in cfg, selected by
+// uidArgs[0] when cfg runs more than one (see Config.BridgeConfigs), without
+// requiring a restart. If the daemon's pairing server is listening
+// (cfg.PairingPort != 0), it mints the token through that live process via
+// POST /pair/mint so the new token supersedes whatever was in a previously
+// printed QR for the same uid; otherwise it mints locally with manager,
+// where supersession only holds until this process exits.
+func cmdPairQR(cfg *config.Config, manager *pairing.Manager, uidArgs []string) {
+	bridges := cfg.BridgeConfigs()
+	var target *config.BridgeConfig
+	if len(uidArgs) > 0 {
+		for i := range bridges {
+			if bridges[i].UID == uidArgs[0] {
+				target = &bridges[i]
+				break
+			}
+		}
+		if target == nil {
+			fmt.Fprintf(os.Stderr, "No configured bridge with uid %q\n", uidArgs[0])
+			os.Exit(1)
+		}
+	} else if len(bridges) == 1 {
+		target = &bridges[0]
+	} else {
+		fmt.Fprintln(os.Stderr, "Usage: openclaw-bridge pair qr <uid> (this daemon runs multiple bridges; uid must be one of:)")
+		for _, bc := range bridges {
+			fmt.Fprintf(os.Stderr, "  %s\n", bc.UID)
+		}
+		os.Exit(1)
+	}
+
+	token, err := mintPairingToken(cfg, manager, target.UID, target.WebhookURL)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to mint pairing token: %v\n", err)
+		os.Exit(1)
+	}
+	renderPairingQR(token)
+}
+
+// mintPairingToken mints a fresh enrollment token for uid/webhookURL,
+// preferring the running daemon's live Manager (via POST /pair/mint) so the
+// mint actually supersedes a previous unused token for the same uid; it
+// falls back to minting locally with manager when no pairing server is
+// configured or it can't be reached.
+func mintPairingToken(cfg *config.Config, manager *pairing.Manager, uid, webhookURL string) (string, error) {
+	if cfg.PairingPort != 0 {
+		if token, err := mintPairingTokenRemote(cfg.PairingPort, uid, webhookURL); err == nil {
+			return token, nil
+		}
+	}
+	return manager.MintToken(webhookURL, uid, pairing.DefaultTokenTTL)
+}
+
+// pairMintRequest/pairMintResponse mirror pairing.mintRequest/mintResponse's
+// JSON shape, which POST /pair/mint expects and returns.
+type pairMintRequest struct {
+	UID   string `json:"uid"`
+	WSURL string `json:"wsUrl"`
+}
+
+type pairMintResponse struct {
+	Token string `json:"token"`
+}
+
+func mintPairingTokenRemote(port int, uid, webhookURL string) (string, error) {
+	body, err := json.Marshal(pairMintRequest{UID: uid, WSURL: webhookURL})
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("http://127.0.0.1:%d/pair/mint", port)
+	httpClient := &http.Client{Timeout: 5 * time.Second}
+	resp, err := httpClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("pairing server returned %s", resp.Status)
+	}
+
+	var parsed pairMintResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return "", err
+	}
+	return parsed.Token, nil
+}
+
+// renderPairingQR prints token as a scannable QR code, matching the format
+// printConnectionQRCode has always used: the token is the only thing a
+// captured screenshot exposes, never the raw webhookURL/uid it decodes to.
+func renderPairingQR(token string) {
+	payloadBytes, err := json.Marshal(map[string]string{"token": token})
+	if err != nil {
+		logging.Printf("[Main] Failed to build QR payload: %v", err)
+		return
+	}
+	payload := string(payloadBytes)
+
+	fmt.Println("Scan this QR with openclaw-mapp to connect:")
+	renderQRCode(payload)
+	fmt.Printf("QR payload: %s\n\n", payload)
+}
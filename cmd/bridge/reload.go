@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/bridge"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/config"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/logging"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/openclaw"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/sessions"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/webhook"
+)
+
+// runtimeClients holds the live webhook/OpenClaw clients and fan-out
+// sender for a running single-bridge process (see cmdRun), so
+// reloadConfig can swap them out in place on a config reload (SIGHUP or a
+// detected bridge.json write) and every other reader (the shutdown
+// coordinator, the /status provider) always sees the current ones.
+type runtimeClients struct {
+	reloadMu       sync.Mutex
+	webhookClient  *webhook.Client
+	clawdbotClient *openclaw.Client
+	fanoutSender   *webhook.FanoutSender
+}
+
+// reloadConfig re-reads bridge.json and applies any change to the webhook
+// URL, UID, session scope, or OpenClaw gateway settings by reconnecting
+// only the affected subsystem - the session store, event emitter, and
+// fan-out targets are left untouched. cfg is updated in place (rather than
+// replaced) so every other holder of the pointer - the /status provider,
+// in particular - observes the change without needing its own reload.
+func reloadConfig(ctx context.Context, cfg *config.Config, bridgeInstance *bridge.Bridge, rc *runtimeClients) {
+	rc.reloadMu.Lock()
+	defer rc.reloadMu.Unlock()
+
+	newCfg, err := config.Load()
+	if err != nil {
+		logging.Printf("[Main] Config reload failed, keeping previous config: %v", err)
+		return
+	}
+
+	if newCfg.UID != cfg.UID {
+		bridgeInstance.SetUID(newCfg.UID)
+	}
+
+	if newCfg.SessionScope != cfg.SessionScope {
+		bridgeInstance.UpdateSessionScope(sessionScopeFromString(newCfg.SessionScope))
+	}
+
+	if newCfg.WebhookURL != cfg.WebhookURL || !reflect.DeepEqual(newCfg.WebhookTLS, cfg.WebhookTLS) {
+		reconnectWebhook(ctx, newCfg, bridgeInstance, rc)
+	}
+
+	if !reflect.DeepEqual(newCfg.OpenClaw, cfg.OpenClaw) {
+		reconnectOpenClaw(ctx, newCfg, bridgeInstance, rc)
+	}
+
+	*cfg = *newCfg
+	logging.Println("[Main] Config reload applied")
+}
+
+// sessionScopeFromString mirrors the cfg.SessionScope -> sessions.SessionScope
+// switch in cmdRun/runReleaseBridge.
+func sessionScopeFromString(scope string) sessions.SessionScope {
+	if scope == "global" {
+		return sessions.SessionScopeGlobal
+	}
+	return sessions.SessionScopePerSender
+}
+
+// watchConfigDir is a fallback to SIGHUP for environments that can send a
+// file write but not a signal (e.g. a config management tool dropping a
+// new bridge.json into place): it watches dir for writes to bridge.json
+// and calls onReload for each one, until ctx is done. A watcher that fails
+// to start is logged and skipped - SIGHUP-triggered reload still works
+// without it.
+func watchConfigDir(ctx context.Context, dir string, onReload func()) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		logging.Printf("[Main] Config file watch disabled: %v", err)
+		return
+	}
+	if err := watcher.Add(dir); err != nil {
+		logging.Printf("[Main] Config file watch disabled: %v", err)
+		watcher.Close()
+		return
+	}
+
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Base(event.Name) != "bridge.json" || event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+					continue
+				}
+				logging.Println("[Main] Detected bridge.json change, reloading config...")
+				onReload()
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logging.Printf("[Main] Config file watch error: %v", err)
+			}
+		}
+	}()
+}
+
+// reconnectWebhook builds a new webhook.Client from cfg, connects it, and
+// swaps it into bridgeInstance and rc.fanoutSender's primary sink,
+// closing the old client once the swap is visible to new callers.
+func reconnectWebhook(ctx context.Context, cfg *config.Config, bridgeInstance *bridge.Bridge, rc *runtimeClients) {
+	newClient, err := webhook.NewClient(
+		cfg.WebhookURL,
+		bridgeInstance.HandleWebhookMessage,
+		cfg.UID,
+		webhookTLSConfig(cfg.WebhookTLS),
+		nil,
+		nil,
+	)
+	if err != nil {
+		logging.Printf("[Main] Config reload: failed to build new webhook client: %v", err)
+		return
+	}
+	if err := newClient.Connect(ctx); err != nil {
+		logging.Printf("[Main] Config reload: failed to connect new webhook client: %v", err)
+		return
+	}
+
+	old := bridgeInstance.UpdateWebhookClient(newClient)
+	rc.fanoutSender.UpdatePrimary(newClient)
+	rc.webhookClient = newClient
+	if old != nil {
+		old.Close()
+	}
+	logging.Println("[Main] Config reload: webhook client reconnected")
+}
+
+// reconnectOpenClaw builds a new openclaw.Client from cfg, connects it, and
+// registers it in bridgeInstance's ClientRegistry under DefaultTenantID,
+// closing the old client once the swap is visible to new callers.
+func reconnectOpenClaw(ctx context.Context, cfg *config.Config, bridgeInstance *bridge.Bridge, rc *runtimeClients) {
+	newClient, err := openclaw.NewClient(
+		gatewayURL(cfg.OpenClaw),
+		cfg.OpenClaw.GatewayToken,
+		cfg.OpenClaw.AgentID,
+		openclawTLSConfig(cfg.OpenClaw.TLS),
+		nil,
+		nil,
+	)
+	if err != nil {
+		logging.Printf("[Main] Config reload: failed to build new OpenClaw client: %v", err)
+		return
+	}
+	newClient.SetEventCallback(bridgeInstance.HandleOpenClawEvent)
+	if err := newClient.Connect(ctx); err != nil {
+		logging.Printf("[Main] Config reload: failed to connect new OpenClaw client: %v", err)
+		return
+	}
+
+	old := rc.clawdbotClient
+	bridgeInstance.Registry().Register(bridge.DefaultTenantID, newClient)
+	rc.clawdbotClient = newClient
+	if old != nil {
+		old.Close()
+	}
+	logging.Println("[Main] Config reload: OpenClaw client reconnected")
+}
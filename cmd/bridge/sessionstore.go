@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/sternelee/openclaw-webhook-bridge/internal/config"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/sessions"
+)
+
+// buildSessionStoreConfig maps a BridgeConfig's SessionStore selection onto
+// a sessions.StoreConfig, the way cmdRun and runReleaseBridge both need to
+// before calling sessions.NewStore - kept in one place so a driver added to
+// one build (see sessions.StoreDriverEtcd) can't quietly go unsupported in
+// the other.
+func buildSessionStoreConfig(storePath string, ss config.SessionStoreConfig) (*sessions.StoreConfig, error) {
+	storeConfig := sessions.DefaultStoreConfig(storePath)
+	switch ss.Driver {
+	case "", sessions.StoreDriverFile:
+		// storeConfig already points at storePath.
+	case sessions.StoreDriverBolt:
+		storeConfig.Driver = sessions.StoreDriverBolt
+		storeConfig.BoltPath = ss.DSN
+	case sessions.StoreDriverRedis:
+		storeConfig.Driver = sessions.StoreDriverRedis
+		storeConfig.RedisAddr = ss.DSN
+	case sessions.StoreDriverEtcd:
+		storeConfig.Driver = sessions.StoreDriverEtcd
+		storeConfig.EtcdEndpoint = ss.DSN
+	default:
+		return nil, fmt.Errorf("unknown session_store.driver %q", ss.Driver)
+	}
+	return storeConfig, nil
+}
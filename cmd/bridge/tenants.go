@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sternelee/openclaw-webhook-bridge/internal/bridge"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/config"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/logging"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/openclaw"
+)
+
+// buildClientRegistry builds a bridge.ClientRegistry with defaultClient
+// registered under bridge.DefaultTenantID plus one additional OpenClaw
+// client per cfg.Tenants entry, so cmdRun and runReleaseBridge build
+// identical multi-tenant registries. Only the top-level/default bridge
+// entry routes extra tenants (see config.Config.Tenants); callers for any
+// other BridgeConfig should keep using bridge.NewSingleClientRegistry.
+func buildClientRegistry(cfg *config.Config, defaultClient *openclaw.Client) (*bridge.ClientRegistry, error) {
+	registry := bridge.NewClientRegistry()
+	registry.Register(bridge.DefaultTenantID, defaultClient)
+
+	for _, tenant := range cfg.Tenants {
+		client, err := openclaw.NewClient(
+			gatewayURL(tenant.OpenClaw),
+			tenant.OpenClaw.GatewayToken,
+			tenant.OpenClaw.AgentID,
+			openclawTLSConfig(tenant.OpenClaw.TLS),
+			nil,
+			nil,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("create OpenClaw client for tenant %q: %w", tenant.TenantID, err)
+		}
+		registry.Register(tenant.TenantID, client)
+	}
+
+	return registry, nil
+}
+
+// wireTenantEventCallbacks routes every client in registry's OpenClaw events
+// through bridgeInstance, tagged with that client's tenant ID, so a single
+// bridge process can fan several agents' events out to the same webhook.
+func wireTenantEventCallbacks(registry *bridge.ClientRegistry, bridgeInstance *bridge.Bridge) {
+	for _, tenantID := range registry.Tenants() {
+		tenantID := tenantID
+		client, ok := registry.Get(tenantID)
+		if !ok {
+			continue
+		}
+		client.SetEventCallback(func(data []byte) {
+			bridgeInstance.HandleOpenClawEventForTenant(tenantID, data)
+		})
+	}
+}
+
+// connectRegistryClients connects every client in registry, stopping at (and
+// returning) the first error so the caller can fail startup the same way a
+// single-tenant Connect failure already does.
+func connectRegistryClients(ctx context.Context, registry *bridge.ClientRegistry) error {
+	for _, tenantID := range registry.Tenants() {
+		client, ok := registry.Get(tenantID)
+		if !ok {
+			continue
+		}
+		if err := client.Connect(ctx); err != nil {
+			return fmt.Errorf("connect tenant %q: %w", tenantID, err)
+		}
+	}
+	return nil
+}
+
+// closeRegistryClients closes every client in registry, logging (rather than
+// returning) failures so one stuck tenant can't stop the others from being
+// closed during shutdown.
+func closeRegistryClients(registry *bridge.ClientRegistry) {
+	for _, tenantID := range registry.Tenants() {
+		client, ok := registry.Get(tenantID)
+		if !ok {
+			continue
+		}
+		if err := client.Close(); err != nil {
+			logging.Printf("[Main] Failed to close OpenClaw client for tenant %q: %v", tenantID, err)
+		}
+	}
+}
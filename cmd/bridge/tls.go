@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/sternelee/openclaw-webhook-bridge/internal/config"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/openclaw"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/webhook"
+)
+
+// gatewayURL builds the gateway's WebSocket URL from its port, using wss://
+// when oc.TLS is set.
+func gatewayURL(oc config.OpenClawConfig) string {
+	scheme := "ws"
+	if oc.TLS != nil {
+		scheme = "wss"
+	}
+	return fmt.Sprintf("%s://127.0.0.1:%d", scheme, oc.GatewayPort)
+}
+
+// openclawTLSConfig converts the config package's generic TLSConfig into
+// openclaw.TLSConfig, or nil if tlsConfig is nil.
+func openclawTLSConfig(tlsConfig *config.TLSConfig) *openclaw.TLSConfig {
+	if tlsConfig == nil {
+		return nil
+	}
+	return &openclaw.TLSConfig{
+		CAFile:     tlsConfig.CAFile,
+		CertFile:   tlsConfig.CertFile,
+		KeyFile:    tlsConfig.KeyFile,
+		ServerName: tlsConfig.ServerName,
+		Insecure:   tlsConfig.Insecure,
+	}
+}
+
+// webhookTLSConfig converts the config package's generic TLSConfig into
+// webhook.TLSConfig, or nil if tlsConfig is nil.
+func webhookTLSConfig(tlsConfig *config.TLSConfig) *webhook.TLSConfig {
+	if tlsConfig == nil {
+		return nil
+	}
+	return &webhook.TLSConfig{
+		CAFile:     tlsConfig.CAFile,
+		CertFile:   tlsConfig.CertFile,
+		KeyFile:    tlsConfig.KeyFile,
+		ServerName: tlsConfig.ServerName,
+		Insecure:   tlsConfig.Insecure,
+	}
+}
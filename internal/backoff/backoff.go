@@ -0,0 +1,66 @@
+// Package backoff implements AWS-style decorrelated jitter backoff, the
+// reconnect delay shared by openclaw.Client and webhook.Client: it spreads
+// out simultaneous reconnect attempts across many bridges far better than a
+// plain doubling backoff does, since each delay is randomized relative to
+// the last rather than deterministic.
+package backoff
+
+import (
+	"math/rand"
+	"time"
+)
+
+// Config parametrizes a Decorrelated sequence.
+type Config struct {
+	// Base is both the floor of every delay and the starting point of the
+	// sequence. Defaults to 1s (see DefaultConfig).
+	Base time.Duration
+	// Cap bounds the maximum delay. Defaults to 30s (see DefaultConfig).
+	Cap time.Duration
+}
+
+// DefaultConfig returns Base=1s, Cap=30s.
+func DefaultConfig() Config {
+	return Config{Base: 1 * time.Second, Cap: 30 * time.Second}
+}
+
+// Decorrelated generates successive delays via
+// sleep = min(cap, random_between(base, prev*3)), starting at prev=base, as
+// described in https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/.
+// Not safe for concurrent use.
+type Decorrelated struct {
+	cfg  Config
+	prev time.Duration
+}
+
+// NewDecorrelated builds a Decorrelated sequence from cfg. A zero Base or
+// Cap falls back to DefaultConfig's.
+func NewDecorrelated(cfg Config) *Decorrelated {
+	def := DefaultConfig()
+	if cfg.Base <= 0 {
+		cfg.Base = def.Base
+	}
+	if cfg.Cap <= 0 {
+		cfg.Cap = def.Cap
+	}
+	return &Decorrelated{cfg: cfg, prev: cfg.Base}
+}
+
+// Next returns the next delay in the sequence and advances prev to it.
+func (d *Decorrelated) Next() time.Duration {
+	span := d.prev*3 - d.cfg.Base
+	if span <= 0 {
+		span = d.cfg.Base
+	}
+	delay := d.cfg.Base + time.Duration(rand.Int63n(int64(span)))
+	if delay > d.cfg.Cap {
+		delay = d.cfg.Cap
+	}
+	d.prev = delay
+	return delay
+}
+
+// Reset restarts the sequence at Base, as if no attempt had been made yet.
+func (d *Decorrelated) Reset() {
+	d.prev = d.cfg.Base
+}
@@ -0,0 +1,38 @@
+package backoff
+
+import "testing"
+
+func TestDecorrelatedStaysWithinBounds(t *testing.T) {
+	cfg := Config{Base: 10, Cap: 100}
+	d := NewDecorrelated(cfg)
+
+	for i := 0; i < 1000; i++ {
+		delay := d.Next()
+		if delay < cfg.Base || delay > cfg.Cap {
+			t.Fatalf("Next() = %v, want within [%v, %v]", delay, cfg.Base, cfg.Cap)
+		}
+	}
+}
+
+func TestDecorrelatedResetRestartsAtBase(t *testing.T) {
+	cfg := Config{Base: 10, Cap: 100}
+	d := NewDecorrelated(cfg)
+
+	for i := 0; i < 10; i++ {
+		d.Next()
+	}
+	d.Reset()
+
+	if d.prev != cfg.Base {
+		t.Fatalf("prev after Reset = %v, want %v", d.prev, cfg.Base)
+	}
+}
+
+func TestNewDecorrelatedDefaultsZeroFields(t *testing.T) {
+	d := NewDecorrelated(Config{})
+	def := DefaultConfig()
+
+	if d.cfg.Base != def.Base || d.cfg.Cap != def.Cap {
+		t.Fatalf("cfg = %+v, want %+v", d.cfg, def)
+	}
+}
@@ -1,13 +1,21 @@
 package bridge
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/sternelee/openclaw-webhook-bridge/internal/bridge/emitter"
 	"github.com/sternelee/openclaw-webhook-bridge/internal/commands"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/metrics"
 	"github.com/sternelee/openclaw-webhook-bridge/internal/openclaw"
 	"github.com/sternelee/openclaw-webhook-bridge/internal/sessions"
 	"github.com/sternelee/openclaw-webhook-bridge/internal/webhook"
@@ -15,35 +23,126 @@ import (
 
 // Bridge is a simple passthrough between Webhook and OpenClaw with session management
 type Bridge struct {
+	// configMu guards webhookClient and sessionScope, the two fields
+	// UpdateWebhookClient/UpdateSessionScope swap on a config hot-reload
+	// (see cmd/bridge's SIGHUP handling). A message already in flight holds
+	// whatever value it read before the swap, so a reload never blocks
+	// waiting for in-flight work to drain - it just takes effect for the
+	// next message.
+	configMu       sync.RWMutex
 	webhookClient  *webhook.Client
-	clawdbotClient *openclaw.Client
-	commandHandler *commands.CommandHandler
-	agentID        string
+	registry       *ClientRegistry
+	tenantResolver TenantResolver
 	uid            string // Unique ID for this bridge instance
 	sessionStore   *sessions.Store
 	sessionScope   sessions.SessionScope
+	emitter        *emitter.BufferedEmitter
+	resetMatcher   sessions.ResetMatcher
+
+	// idempotencyStore dedups retried webhook deliveries by message ID
+	idempotencyStore *sessions.IdempotencyStore
+	// pendingIdem maps an in-flight session key to the idempotency key that
+	// triggered it, so the eventual "complete" response can be remembered.
+	pendingIdem   map[string]string
+	pendingIdemMu sync.Mutex
+
+	sessionSync *sessions.SyncService
+
+	// pendingControlReqs correlates outbound session control requests
+	// (SendControlRequest) with their eventual response, keyed by RequestID.
+	pendingControlReqs   map[string]chan *sessions.SessionControlMessage
+	pendingControlReqsMu sync.Mutex
+
+	// commandHandlers caches one commands.CommandHandler per tenant client,
+	// so the Gateway skill-list cache (see CommandHandler.listSkills) and
+	// registered commands survive across messages instead of being rebuilt
+	// on every /command.
+	commandHandlers   map[*openclaw.Client]*commands.CommandHandler
+	commandHandlersMu sync.Mutex
+
+	// watchingSessions is set once WatchSessionChanges successfully
+	// subscribes to the session store's change feed, so
+	// observeSessionActive can stop polling Load on every message.
+	watchingSessions atomic.Bool
+}
+
+// SessionStoreOption configures optional behavior passed to SetSessionStore.
+type SessionStoreOption func(*sessionStoreConfig)
+
+type sessionStoreConfig struct {
+	syncTransport sessions.SyncTransport
+}
+
+// WithSync replicates the session store across bridge replicas over
+// transport (e.g. Redis pub/sub or NATS), so a message routed to one
+// instance finds the session another instance created. See
+// sessions.SyncService for delta/resync semantics.
+func WithSync(transport sessions.SyncTransport) SessionStoreOption {
+	return func(cfg *sessionStoreConfig) {
+		cfg.syncTransport = transport
+	}
 }
 
-// NewBridge creates a new bridge
+// NewBridge creates a new single-agent bridge. It is a thin wrapper over
+// NewMultiTenantBridge with a one-entry registry, so existing single-agent
+// setups keep working unchanged.
 func NewBridge(webhookClient *webhook.Client, clawdbotClient *openclaw.Client) *Bridge {
-	agentID := ""
-	if clawdbotClient != nil {
-		agentID = clawdbotClient.AgentID()
+	return NewMultiTenantBridge(webhookClient, NewSingleClientRegistry(clawdbotClient), nil)
+}
+
+// NewMultiTenantBridge creates a bridge backed by a ClientRegistry so a
+// single bridge process can route webhook messages to several OpenClaw
+// agents/tenants. A nil resolver defaults to DefaultTenantResolver.
+func NewMultiTenantBridge(webhookClient *webhook.Client, registry *ClientRegistry, resolver TenantResolver) *Bridge {
+	if registry == nil {
+		registry = NewClientRegistry()
+	}
+	if resolver == nil {
+		resolver = DefaultTenantResolver{}
 	}
-	// Create command handler with openclaw client as gateway client
-	cmdHandler := commands.NewCommandHandler(clawdbotClient)
 	return &Bridge{
-		webhookClient:  webhookClient,
-		clawdbotClient: clawdbotClient,
-		commandHandler: cmdHandler,
-		agentID:        agentID,
-		sessionScope:   sessions.SessionScopePerSender, // Default
+		webhookClient:      webhookClient,
+		registry:           registry,
+		tenantResolver:     resolver,
+		sessionScope:       sessions.SessionScopePerSender, // Default
+		resetMatcher:       sessions.NewDefaultResetMatcher(),
+		pendingIdem:        make(map[string]string),
+		pendingControlReqs: make(map[string]chan *sessions.SessionControlMessage),
 	}
 }
 
 // SetWebhookClient sets the webhook client after construction
 func (b *Bridge) SetWebhookClient(client *webhook.Client) {
+	b.configMu.Lock()
 	b.webhookClient = client
+	b.configMu.Unlock()
+}
+
+// UpdateWebhookClient atomically swaps in a new webhook client - e.g. when
+// a config reload changes webhook_url - and returns the previous one so
+// the caller can Close it once it's done draining in-flight sends.
+func (b *Bridge) UpdateWebhookClient(client *webhook.Client) *webhook.Client {
+	b.configMu.Lock()
+	old := b.webhookClient
+	b.webhookClient = client
+	b.configMu.Unlock()
+	log.Printf("[Bridge] Webhook client swapped")
+	return old
+}
+
+// currentWebhookClient returns the active webhook client, safe to call
+// concurrently with UpdateWebhookClient.
+func (b *Bridge) currentWebhookClient() *webhook.Client {
+	b.configMu.RLock()
+	defer b.configMu.RUnlock()
+	return b.webhookClient
+}
+
+// Registry returns the bridge's ClientRegistry, so callers that need to
+// swap an OpenClaw client on a config reload (see UpdateWebhookClient) can
+// call Registry().Register(tenantID, newClient) directly.
+func (b *Bridge) Registry() *ClientRegistry {
+	return b.registry
 }
 
 // SetUID sets the unique ID for this bridge
@@ -52,20 +151,106 @@ func (b *Bridge) SetUID(uid string) {
 	log.Printf("[Bridge] Bridge UID set to: %s", b.uid)
 }
 
-// SetSessionStore configures the session store
-func (b *Bridge) SetSessionStore(store *sessions.Store) {
+// SetSessionStore configures the session store. Passing WithSync(transport)
+// also starts cross-replica replication: every mutation is published as a
+// delta to transport, and deltas from other replicas are applied with
+// last-writer-wins semantics.
+func (b *Bridge) SetSessionStore(store *sessions.Store, opts ...SessionStoreOption) {
 	b.sessionStore = store
 	log.Printf("[Bridge] Session store configured")
+
+	cfg := &sessionStoreConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.syncTransport == nil {
+		return
+	}
+
+	syncService := sessions.NewSyncService(store, cfg.syncTransport)
+	if err := syncService.Start(); err != nil {
+		log.Printf("[Bridge] Failed to start session sync: %v", err)
+		return
+	}
+	b.sessionSync = syncService
+	log.Printf("[Bridge] Session store sync enabled")
+}
+
+// Close releases background resources owned by the bridge (the event
+// emitter and, if configured, cross-replica session sync).
+func (b *Bridge) Close() error {
+	var firstErr error
+	if b.sessionSync != nil {
+		if err := b.sessionSync.Close(); err != nil {
+			firstErr = err
+		}
+	}
+	if b.emitter != nil {
+		if err := b.emitter.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
 }
 
 // SetSessionScope sets the session scope
 func (b *Bridge) SetSessionScope(scope sessions.SessionScope) {
+	b.configMu.Lock()
 	b.sessionScope = scope
+	b.configMu.Unlock()
 	log.Printf("[Bridge] Session scope set to: %s", scope)
 }
 
+// UpdateSessionScope atomically swaps the session scope used to resolve
+// new sessions - e.g. when a config reload flips session_scope between
+// "global" and "per-sender". Sessions already resolved keep their existing
+// key; only messages resolved after the swap see the new scope.
+func (b *Bridge) UpdateSessionScope(scope sessions.SessionScope) {
+	b.configMu.Lock()
+	b.sessionScope = scope
+	b.configMu.Unlock()
+	log.Printf("[Bridge] Session scope updated to: %s", scope)
+}
+
+// currentSessionScope returns the active session scope, safe to call
+// concurrently with UpdateSessionScope.
+func (b *Bridge) currentSessionScope() sessions.SessionScope {
+	b.configMu.RLock()
+	defer b.configMu.RUnlock()
+	return b.sessionScope
+}
+
+// SetResetMatcher configures the matcher used to detect session reset
+// triggers in incoming webhook messages. Defaults to
+// sessions.NewDefaultResetMatcher(), which reproduces the bridge's
+// historical "/new" / "/reset" exact-match behavior.
+func (b *Bridge) SetResetMatcher(m sessions.ResetMatcher) {
+	b.resetMatcher = m
+	log.Printf("[Bridge] Reset matcher configured")
+}
+
+// SetIdempotencyStore configures the dedup store used to short-circuit
+// retried webhook deliveries
+func (b *Bridge) SetIdempotencyStore(store *sessions.IdempotencyStore) {
+	b.idempotencyStore = store
+	log.Printf("[Bridge] Idempotency store configured")
+}
+
+// SetEmitter configures the durable emitter used to deliver OpenClaw events
+// to the webhook. When set, HandleOpenClawEvent writes through it instead
+// of calling sendToWebhook directly, so a transient webhook outage buffers
+// events on disk rather than dropping them.
+func (b *Bridge) SetEmitter(e *emitter.BufferedEmitter) {
+	b.emitter = e
+	log.Printf("[Bridge] Event emitter configured")
+}
+
 // HandleWebhookMessage handles a message from the webhook and forwards to OpenClaw
 func (b *Bridge) HandleWebhookMessage(data []byte) error {
+	defer prometheus.NewTimer(metrics.MessageDuration.WithLabelValues("webhook_message")).ObserveDuration()
+	metrics.WebhookMessagesTotal.WithLabelValues("inbound", b.uid).Inc()
+	defer b.observeSessionActive()
+
 	log.Printf("[Bridge] Webhook -> OpenClaw: %s", string(data))
 
 	// Check for session control messages first
@@ -85,18 +270,27 @@ func (b *Bridge) HandleWebhookMessage(data []byte) error {
 		}
 	}
 
+	// Resolve which tenant/agent this message belongs to
+	tenantID, clawdbotClient, ok := b.resolveClient(data)
+	if !ok {
+		log.Printf("[Bridge] No OpenClaw client registered for tenant %q, dropping message", tenantID)
+		return nil
+	}
+	agentID := clawdbotClient.AgentID()
+
 	// Parse the message to extract content and session
 	var msg struct {
-		ID       string `json:"id"`
-		Content  string `json:"content"`
-		Session  string `json:"session"`
-		PeerKind string `json:"peerKind"`
-		PeerID   string `json:"peerId"`
-		ChatType string `json:"chatType"`
-		ChatID   string `json:"chatId"`
-		SenderID string `json:"senderId"`
-		TopicID  string `json:"topicId"`
-		ThreadID string `json:"threadId"`
+		ID             string `json:"id"`
+		Content        string `json:"content"`
+		Session        string `json:"session"`
+		PeerKind       string `json:"peerKind"`
+		PeerID         string `json:"peerId"`
+		ChatType       string `json:"chatType"`
+		ChatID         string `json:"chatId"`
+		SenderID       string `json:"senderId"`
+		TopicID        string `json:"topicId"`
+		ThreadID       string `json:"threadId"`
+		IdempotencyKey string `json:"idempotencyKey"`
 	}
 	if err := json.Unmarshal(data, &msg); err != nil {
 		log.Printf("[Bridge] Failed to parse webhook message: %v", err)
@@ -110,9 +304,25 @@ func (b *Bridge) HandleWebhookMessage(data []byte) error {
 		return nil
 	}
 
+	// Dedup retried deliveries of the same message before doing any work
+	idemKey := strings.TrimSpace(msg.IdempotencyKey)
+	if idemKey == "" {
+		idemKey = strings.TrimSpace(msg.ID)
+	}
+	if b.idempotencyStore != nil && idemKey != "" {
+		if replay, seen := b.idempotencyStore.Seen(idemKey); seen {
+			log.Printf("[Bridge] Duplicate message %s within dedup window, replaying response", idemKey)
+			if replay != nil {
+				b.sendToWebhook(replay)
+			}
+			return nil
+		}
+		b.idempotencyStore.Reserve(idemKey)
+	}
+
 	// Check if this is a command (starts with /)
 	if commands.IsCommand(msg.Content) {
-		return b.handleCommand(msg.Content, msg.Session, msg.ID)
+		return b.handleCommand(clawdbotClient, msg.Content, msg.Session, msg.ID)
 	}
 
 	// Resolve session key using session scope
@@ -143,29 +353,44 @@ func (b *Bridge) HandleWebhookMessage(data []byte) error {
 	if msg.Session != "" {
 		sessionKey = sessions.NormalizeSessionKey(msg.Session)
 	} else if peerKind != "" && peerID != "" {
-		if resolved, ok := sessions.BuildWebhookSessionKey(sessions.WebhookSessionParams{
-			AgentID:  b.agentID,
+		groupParams := sessions.WebhookSessionParams{
+			AgentID:  agentID,
 			PeerKind: peerKind,
 			PeerID:   peerID,
 			TopicID:  topicID,
 			ThreadID: threadID,
-		}); ok {
+		}
+		if resolved, ok := sessions.BuildWebhookSessionKey(groupParams); ok {
 			sessionKey = resolved
+
+			// DMs collapse every :thread: variant into one conversation for
+			// continuity; group/channel sessions keep their own per-topic
+			// key. See SessionGroup.ShouldCollapseToMain.
+			if b.sessionStore != nil {
+				if group, err := b.sessionStore.LoadGroup(groupParams); err != nil {
+					log.Printf("[Bridge] Failed to load session group for %s:%s: %v", peerKind, peerID, err)
+				} else if group.ShouldCollapseToMain() {
+					sessionKey = group.BaseKey()
+				}
+			}
 		}
 	}
 	if sessionKey == "" {
-		sessionKey = sessions.ResolveSessionKey(b.sessionScope, webhookMsg)
+		sessionKey = sessions.ResolveSessionKey(b.currentSessionScope(), webhookMsg)
+	}
+	// Namespace session keys by tenant once more than one agent is
+	// registered, so concurrent tenants never collide on the same key.
+	if b.registry.Len() > 1 {
+		sessionKey = fmt.Sprintf("tenant:%s:%s", tenantID, sessionKey)
 	}
 
-	log.Printf("[Bridge] Resolved session key: %s (scope: %s)", sessionKey, b.sessionScope)
+	log.Printf("[Bridge] Resolved session key: %s (scope: %s, tenant: %s)", sessionKey, b.currentSessionScope(), tenantID)
 
 	// Check for reset triggers before creating new session
-	resetTriggered := b.isResetTrigger(msg.Content)
+	remainder, resetTriggered := b.resetMatcher.Match(msg.Content)
 	if resetTriggered {
 		log.Printf("[Bridge] Reset trigger detected, will create new session")
-		// Strip reset command from content
-		strippedContent := b.stripResetTrigger(msg.Content)
-		msg.Content = strippedContent
+		msg.Content = remainder
 	}
 
 	// Record session metadata if session store is configured
@@ -215,13 +440,33 @@ func (b *Bridge) HandleWebhookMessage(data []byte) error {
 		}
 	}
 
+	// Remember which idempotency key to resolve once this session's
+	// response comes back from OpenClaw
+	if b.idempotencyStore != nil && idemKey != "" {
+		b.pendingIdemMu.Lock()
+		b.pendingIdem[sessionKey] = idemKey
+		b.pendingIdemMu.Unlock()
+	}
+
 	// Forward as agent request
-	return b.clawdbotClient.SendAgentRequest(msg.Content, sessionKey)
+	return clawdbotClient.SendAgentRequest(msg.Content, sessionKey)
 }
 
-// HandleOpenClawEvent handles an event from OpenClaw and forwards to webhook
+// HandleOpenClawEvent handles an event from the default/single-tenant
+// OpenClaw client and forwards it to the webhook. Kept for backward
+// compatibility with single-agent setups built via NewBridge.
 func (b *Bridge) HandleOpenClawEvent(data []byte) {
-	log.Printf("[Bridge] OpenClaw -> Webhook: %s", string(data))
+	b.HandleOpenClawEventForTenant(DefaultTenantID, data)
+}
+
+// HandleOpenClawEventForTenant handles an event from a specific tenant's
+// OpenClaw client and forwards it to the webhook. Use this (one closure per
+// registered client) to fan events from several agents into one bridge.
+func (b *Bridge) HandleOpenClawEventForTenant(tenantID string, data []byte) {
+	defer prometheus.NewTimer(metrics.MessageDuration.WithLabelValues("openclaw_event")).ObserveDuration()
+	defer b.observeSessionActive()
+
+	log.Printf("[Bridge] OpenClaw[%s] -> Webhook: %s", tenantID, string(data))
 
 	// Parse the event to determine its type
 	var baseEvent struct {
@@ -230,10 +475,15 @@ func (b *Bridge) HandleOpenClawEvent(data []byte) {
 	}
 	if err := json.Unmarshal(data, &baseEvent); err != nil {
 		log.Printf("[Bridge] Failed to parse event type: %v", err)
+		metrics.OpenClawEventsTotal.WithLabelValues("unknown").Inc()
 		// Send raw data anyway
-		b.sendToWebhook(data)
+		if b.registry.Len() > 1 {
+			data = tagWithTenant(data, tenantID)
+		}
+		b.emitToWebhook(data)
 		return
 	}
+	metrics.OpenClawEventsTotal.WithLabelValues(eventMetricType(baseEvent.Type, baseEvent.Event)).Inc()
 
 	// Check if this is a lifecycle event that needs special handling
 	if baseEvent.Event == "lifecycle" || baseEvent.Event == "tick" || baseEvent.Event == "presence" || baseEvent.Event == "health" {
@@ -258,12 +508,130 @@ func (b *Bridge) HandleOpenClawEvent(data []byte) {
 
 	// Convert OpenClaw event format to webhook format
 	convertedData := b.convertEventToWebhookFormat(data, baseEvent.Type)
-	b.sendToWebhook(convertedData)
+	if b.registry.Len() > 1 {
+		convertedData = tagWithTenant(convertedData, tenantID)
+	}
+	b.rememberIdempotentResponse(sessionEvent.SessionKey, convertedData)
+	b.emitToWebhook(convertedData)
+}
+
+// eventMetricType picks the label for metrics.OpenClawEventsTotal: the
+// lifecycle Event field when set (events like "lifecycle"/"tick" carry no
+// Type), otherwise Type, otherwise "unknown".
+func eventMetricType(eventType, lifecycleEvent string) string {
+	if lifecycleEvent != "" {
+		return lifecycleEvent
+	}
+	if eventType != "" {
+		return eventType
+	}
+	return "unknown"
+}
+
+// observeSessionActive samples the session store's current size into
+// metrics.SessionActive. It's called after every webhook message/OpenClaw
+// event rather than on a ticker, since that's every point session count
+// can change. A no-op once WatchSessionChanges is active, since that keeps
+// the same gauge current from the backend's change feed instead.
+func (b *Bridge) observeSessionActive() {
+	if b.sessionStore == nil || b.watchingSessions.Load() {
+		return
+	}
+	view, err := b.sessionStore.LoadReadonly()
+	if err != nil {
+		return
+	}
+	metrics.SessionActive.Set(float64(view.Count()))
+}
+
+// WatchSessionChanges subscribes to the session store's backend-level
+// change feed (see sessions.Store.Watch) and keeps metrics.SessionActive
+// current from the resulting Put/Delete events - including ones made by a
+// peer replica sharing the same backend - instead of relying on
+// observeSessionActive's per-message poll. It returns the Watch error
+// unchanged if the backend doesn't implement sessions.Watcher (e.g. bbolt),
+// which is an expected, non-fatal gap callers should log and fall back to
+// polling for. The subscription runs until ctx is cancelled.
+func (b *Bridge) WatchSessionChanges(ctx context.Context) error {
+	if b.sessionStore == nil {
+		return fmt.Errorf("bridge: no session store configured")
+	}
+
+	events, err := b.sessionStore.Watch(ctx)
+	if err != nil {
+		return err
+	}
+
+	known := make(map[string]struct{})
+	if view, err := b.sessionStore.LoadReadonly(); err == nil {
+		for key := range view.All() {
+			known[key] = struct{}{}
+		}
+	}
+	metrics.SessionActive.Set(float64(len(known)))
+	b.watchingSessions.Store(true)
+
+	go func() {
+		for event := range events {
+			switch event.Op {
+			case sessions.SessionChangeOpPut:
+				known[event.Key] = struct{}{}
+			case sessions.SessionChangeOpDelete:
+				delete(known, event.Key)
+			}
+			metrics.SessionActive.Set(float64(len(known)))
+		}
+	}()
+
+	return nil
+}
+
+// emitToWebhook hands data off for delivery to the webhook, preferring the
+// durable emitter (if configured) over a direct, lossy send.
+func (b *Bridge) emitToWebhook(data []byte) {
+	if data == nil {
+		return
+	}
+	if b.emitter != nil {
+		if err := b.emitter.Emit(data); err != nil {
+			log.Printf("[Bridge] Failed to enqueue event for webhook: %v", err)
+		}
+		return
+	}
+	b.sendToWebhook(data)
+}
+
+// rememberIdempotentResponse records the final response for a session's
+// pending idempotency key, if any, so a retried delivery of the same
+// message can replay it instead of triggering another agent request.
+func (b *Bridge) rememberIdempotentResponse(sessionKey string, convertedData []byte) {
+	if b.idempotencyStore == nil || sessionKey == "" || convertedData == nil {
+		return
+	}
+
+	var typed struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(convertedData, &typed); err != nil || typed.Type != "complete" {
+		return
+	}
+
+	b.pendingIdemMu.Lock()
+	idemKey, ok := b.pendingIdem[sessionKey]
+	if ok {
+		delete(b.pendingIdem, sessionKey)
+	}
+	b.pendingIdemMu.Unlock()
+
+	if ok {
+		b.idempotencyStore.Remember(idemKey, convertedData)
+	}
 }
 
 // sendToWebhook sends data to the webhook client
 func (b *Bridge) sendToWebhook(data []byte) {
-	if err := b.webhookClient.Send(data); err != nil {
+	metrics.WebhookMessagesTotal.WithLabelValues("outbound", b.uid).Inc()
+	if err := b.currentWebhookClient().Send(data); err != nil {
 		log.Printf("[Bridge] Failed to send to webhook: %v", err)
 	}
 }
@@ -386,13 +754,18 @@ func (b *Bridge) handleSessionControlMessage(data []byte) error {
 		return err
 	}
 
+	if ctrlMsg.IsResponse() {
+		b.resolvePendingControlRequest(ctrlMsg)
+		return nil
+	}
+
 	log.Printf("[Bridge] Handling session control: type=%s, key=%s", ctrlMsg.Type, ctrlMsg.Key)
 
 	switch ctrlMsg.Type {
 	case sessions.ControlMessageSessionGet:
 		return b.handleSessionGet(ctrlMsg)
 	case sessions.ControlMessageSessionList:
-		return b.handleSessionList()
+		return b.handleSessionList(ctrlMsg)
 	case sessions.ControlMessageSessionReset:
 		return b.handleSessionReset(ctrlMsg)
 	case sessions.ControlMessageSessionDelete:
@@ -404,6 +777,69 @@ func (b *Bridge) handleSessionControlMessage(data []byte) error {
 	return nil
 }
 
+// resolvePendingControlRequest delivers an inbound response envelope to the
+// SendControlRequest call awaiting it, if any is still pending.
+func (b *Bridge) resolvePendingControlRequest(msg *sessions.SessionControlMessage) {
+	b.pendingControlReqsMu.Lock()
+	ch, ok := b.pendingControlReqs[msg.RequestID]
+	if ok {
+		delete(b.pendingControlReqs, msg.RequestID)
+	}
+	b.pendingControlReqsMu.Unlock()
+
+	if !ok {
+		log.Printf("[Bridge] No pending control request for requestId=%s, dropping response", msg.RequestID)
+		return
+	}
+	ch <- msg
+}
+
+// SendControlRequest sends a session control request to the webhook client
+// and blocks until the correlated response arrives or ctx is done. Callers
+// should set a deadline on ctx; there is no implicit timeout.
+func (b *Bridge) SendControlRequest(ctx context.Context, msg *sessions.SessionControlMessage) (*sessions.SessionControlMessage, error) {
+	if msg.RequestID == "" {
+		msg.RequestID = uuid.NewString()
+	}
+	msg.TransactionType = sessions.TransactionTypeRequest
+
+	ch := make(chan *sessions.SessionControlMessage, 1)
+	b.pendingControlReqsMu.Lock()
+	b.pendingControlReqs[msg.RequestID] = ch
+	b.pendingControlReqsMu.Unlock()
+
+	defer func() {
+		b.pendingControlReqsMu.Lock()
+		delete(b.pendingControlReqs, msg.RequestID)
+		b.pendingControlReqsMu.Unlock()
+	}()
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal control request: %w", err)
+	}
+	if err := b.currentWebhookClient().Send(data); err != nil {
+		return nil, fmt.Errorf("failed to send control request: %w", err)
+	}
+
+	select {
+	case response := <-ch:
+		return response, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// SendControlNotification broadcasts a server-initiated envelope (e.g.
+// session.expired) that has no correlated request.
+func (b *Bridge) SendControlNotification(msgType sessions.ControlMessageType, data interface{}) error {
+	notification, err := sessions.BuildSessionNotification(msgType, data)
+	if err != nil {
+		return err
+	}
+	return b.currentWebhookClient().Send(notification)
+}
+
 // handleSessionGet returns information about a specific session
 func (b *Bridge) handleSessionGet(msg *sessions.SessionControlMessage) error {
 	sessionKey := msg.Key
@@ -413,7 +849,7 @@ func (b *Bridge) handleSessionGet(msg *sessions.SessionControlMessage) error {
 
 	entry, err := b.sessionStore.GetEntry(sessionKey)
 	if err != nil {
-		return b.sendControlResponse(msg.Type, map[string]interface{}{
+		return b.sendControlResponse(msg.RequestID, msg.Type, map[string]interface{}{
 			"error": "Session not found",
 		})
 	}
@@ -427,11 +863,11 @@ func (b *Bridge) handleSessionGet(msg *sessions.SessionControlMessage) error {
 		LastTo:          entry.LastTo,
 	}
 
-	return b.sendControlResponse(msg.Type, response)
+	return b.sendControlResponse(msg.RequestID, msg.Type, response)
 }
 
 // handleSessionList returns all sessions
-func (b *Bridge) handleSessionList() error {
+func (b *Bridge) handleSessionList(msg *sessions.SessionControlMessage) error {
 	store, err := b.sessionStore.Load()
 	if err != nil {
 		return err
@@ -456,7 +892,7 @@ func (b *Bridge) handleSessionList() error {
 		Count:    len(sessionList),
 	}
 
-	return b.sendControlResponse(sessions.ControlMessageSessionList, response)
+	return b.sendControlResponse(msg.RequestID, sessions.ControlMessageSessionList, response)
 }
 
 // handleSessionReset resets a session
@@ -474,12 +910,12 @@ func (b *Bridge) handleSessionReset(msg *sessions.SessionControlMessage) error {
 	})
 
 	if err != nil {
-		return b.sendControlResponse(msg.Type, map[string]interface{}{
+		return b.sendControlResponse(msg.RequestID, msg.Type, map[string]interface{}{
 			"error": "Failed to reset session",
 		})
 	}
 
-	return b.sendControlResponse(msg.Type, map[string]interface{}{
+	return b.sendControlResponse(msg.RequestID, msg.Type, map[string]interface{}{
 		"success": true,
 		"key":     sessionKey,
 	})
@@ -498,25 +934,26 @@ func (b *Bridge) handleSessionDelete(msg *sessions.SessionControlMessage) error
 	})
 
 	if err != nil {
-		return b.sendControlResponse(msg.Type, map[string]interface{}{
+		return b.sendControlResponse(msg.RequestID, msg.Type, map[string]interface{}{
 			"error": "Failed to delete session",
 		})
 	}
 
-	return b.sendControlResponse(msg.Type, map[string]interface{}{
+	return b.sendControlResponse(msg.RequestID, msg.Type, map[string]interface{}{
 		"success": true,
 		"key":     sessionKey,
 	})
 }
 
-// sendControlResponse sends a control message response back to the webhook
-func (b *Bridge) sendControlResponse(msgType sessions.ControlMessageType, data interface{}) error {
-	response, err := sessions.BuildSessionControlResponse(msgType, data)
+// sendControlResponse sends a control message response back to the webhook,
+// echoing requestID so the issuing client can correlate it with its request.
+func (b *Bridge) sendControlResponse(requestID string, msgType sessions.ControlMessageType, data interface{}) error {
+	response, err := sessions.BuildSessionControlResponse(requestID, msgType, data)
 	if err != nil {
 		return err
 	}
 
-	if err := b.webhookClient.Send(response); err != nil {
+	if err := b.currentWebhookClient().Send(response); err != nil {
 		log.Printf("[Bridge] Failed to send control response: %v", err)
 		return err
 	}
@@ -524,56 +961,44 @@ func (b *Bridge) sendControlResponse(msgType sessions.ControlMessageType, data i
 	return nil
 }
 
-// isResetTrigger checks if the message content is a session reset trigger
-func (b *Bridge) isResetTrigger(content string) bool {
-	normalized := normalizeContent(content)
-	for _, trigger := range sessions.DefaultResetTriggers {
-		if normalized == trigger {
-			return true
-		}
-	}
-	return false
+// getCurrentTimestamp returns the current timestamp in milliseconds
+func getCurrentTimestamp() int64 {
+	return time.Now().UnixMilli()
 }
 
-// stripResetTrigger strips the reset trigger from the content
-func (b *Bridge) stripResetTrigger(content string) string {
-	normalized := normalizeContent(content)
-	for _, trigger := range sessions.DefaultResetTriggers {
-		// Check if content starts with trigger followed by space or end
-		if len(normalized) == len(trigger) && normalized == trigger {
-			return "" // Just the trigger, return empty
-		}
-		if len(normalized) > len(trigger)+1 {
-			prefix := normalized[:len(trigger)+1]
-			if prefix == trigger+" " {
-				// Return the rest after the trigger and space
-				return content[len(trigger)+1:]
-			}
-		}
-	}
-	return content
-}
+// commandHandlerFor returns the cached CommandHandler for client, creating
+// one on first use.
+func (b *Bridge) commandHandlerFor(client *openclaw.Client) *commands.CommandHandler {
+	b.commandHandlersMu.Lock()
+	defer b.commandHandlersMu.Unlock()
 
-// normalizeContent normalizes content for trigger matching
-func normalizeContent(content string) string {
-	trimmed := content
-	if len(trimmed) > 100 {
-		trimmed = trimmed[:100]
+	if b.commandHandlers == nil {
+		b.commandHandlers = make(map[*openclaw.Client]*commands.CommandHandler)
+	}
+	if h, ok := b.commandHandlers[client]; ok {
+		return h
 	}
-	return trimmed
+
+	h := commands.NewCommandHandler(client, b.sessionStore, b.isWebhookConnected)
+	b.commandHandlers[client] = h
+	return h
 }
 
-// getCurrentTimestamp returns the current timestamp in milliseconds
-func getCurrentTimestamp() int64 {
-	return time.Now().UnixMilli()
+// isWebhookConnected reports whether this bridge's webhook client currently
+// has a live connection; used by CommandHandler's /status command.
+func (b *Bridge) isWebhookConnected() bool {
+	client := b.currentWebhookClient()
+	return client != nil && client.IsConnected()
 }
 
-// handleCommand processes a command message and sends the response back
-func (b *Bridge) handleCommand(content, session, messageID string) error {
+// handleCommand processes a command message against a specific tenant's
+// OpenClaw client and sends the response back
+func (b *Bridge) handleCommand(clawdbotClient *openclaw.Client, content, session, messageID string) error {
 	log.Printf("[Bridge] Processing command: %s", content)
 
 	// Handle the command
-	response, err := b.commandHandler.HandleCommand(content)
+	commandHandler := b.commandHandlerFor(clawdbotClient)
+	response, err := commandHandler.HandleCommand(content, b.uid, session)
 	if err != nil {
 		// Check if this is a forward request
 		if strings.HasPrefix(err.Error(), "FORWARD_TO_GATEWAY:") {
@@ -582,7 +1007,7 @@ func (b *Bridge) handleCommand(content, session, messageID string) error {
 			log.Printf("[Bridge] Forwarding to Gateway: %s", forwardContent)
 
 			// Send to OpenClaw Gateway as an agent request
-			if err := b.clawdbotClient.SendAgentRequest(forwardContent, session); err != nil {
+			if err := clawdbotClient.SendAgentRequest(forwardContent, session); err != nil {
 				log.Printf("[Bridge] Failed to forward to Gateway: %v", err)
 				return err
 			}
@@ -604,7 +1029,7 @@ func (b *Bridge) handleCommand(content, session, messageID string) error {
 	}
 
 	// Send response back to webhook
-	if err := b.webhookClient.Send(responseData); err != nil {
+	if err := b.currentWebhookClient().Send(responseData); err != nil {
 		log.Printf("[Bridge] Failed to send command response: %v", err)
 		return err
 	}
@@ -0,0 +1,450 @@
+// Package emitter provides a durable, async event emitter that sits between
+// Bridge and webhook.Client. It exists so a transient webhook outage doesn't
+// silently lose OpenClaw events mid-conversation: events are written to an
+// append-only write-ahead log (rotating segments, fsync on close) before
+// being queued in memory, and a segment is only deleted once every event in
+// it has been resolved: acknowledged by a successful Sender.Send call, or
+// discarded under BackpressureDropOldest.
+package emitter
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// BackpressurePolicy controls what happens when the pending queue reaches
+// Config.RingSize.
+type BackpressurePolicy string
+
+const (
+	// BackpressureBlock makes Emit wait until room frees up (default).
+	BackpressureBlock BackpressurePolicy = "block"
+	// BackpressureDropOldest discards the oldest unacked event to make room.
+	BackpressureDropOldest BackpressurePolicy = "drop-oldest"
+)
+
+// Sender is the subset of webhook.Client used to deliver buffered events.
+type Sender interface {
+	Send(data []byte) error
+}
+
+// Config configures a BufferedEmitter.
+type Config struct {
+	// Dir is the directory used to store WAL segments.
+	Dir string
+	// RingSize bounds how many unacked events may be pending at once.
+	RingSize int
+	// SegmentMaxEntries is how many events are written to a segment file
+	// before it is rotated.
+	SegmentMaxEntries int
+	// Backpressure controls behavior when RingSize is reached.
+	Backpressure BackpressurePolicy
+	// RetryInterval is how long to wait between retries of a failed Send.
+	RetryInterval time.Duration
+}
+
+// DefaultConfig returns sane defaults for a WAL directory.
+func DefaultConfig(dir string) *Config {
+	return &Config{
+		Dir:               dir,
+		RingSize:          1024,
+		SegmentMaxEntries: 256,
+		Backpressure:      BackpressureBlock,
+		RetryInterval:     2 * time.Second,
+	}
+}
+
+// Metrics reports emitter queue health.
+type Metrics struct {
+	Pending int
+	Dropped uint64
+	Acked   uint64
+}
+
+// segment is an in-memory view of one WAL segment file.
+type segment struct {
+	seq     int
+	path    string
+	entries [][]byte
+	acked   int // number of entries from this segment already resolved (sent or dropped)
+}
+
+func (s *segment) pending() int {
+	return len(s.entries) - s.acked
+}
+
+var segmentFileRe = regexp.MustCompile(`^segment-(\d+)\.wal$`)
+
+// BufferedEmitter durably buffers events destined for the webhook.
+type BufferedEmitter struct {
+	config *Config
+	sender Sender
+
+	mu       sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	segments []*segment
+	dropped  uint64
+	acked    uint64
+
+	activeFile *os.File
+	nextSeq    int
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewBufferedEmitter creates a BufferedEmitter, replaying any unacked
+// segments left over from a previous run.
+func NewBufferedEmitter(sender Sender, config *Config) (*BufferedEmitter, error) {
+	if config == nil {
+		return nil, fmt.Errorf("emitter: config is required")
+	}
+	if err := os.MkdirAll(config.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("emitter: failed to create dir: %w", err)
+	}
+
+	e := &BufferedEmitter{
+		config: config,
+		sender: sender,
+	}
+	e.notEmpty = sync.NewCond(&e.mu)
+	e.notFull = sync.NewCond(&e.mu)
+
+	if err := e.loadSegments(); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// loadSegments reads existing segment files back into memory so they can be
+// replayed, and determines the next segment sequence number.
+func (e *BufferedEmitter) loadSegments() error {
+	files, err := os.ReadDir(e.config.Dir)
+	if err != nil {
+		return fmt.Errorf("emitter: failed to read dir: %w", err)
+	}
+
+	var seqs []int
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		m := segmentFileRe.FindStringSubmatch(f.Name())
+		if m == nil {
+			continue
+		}
+		seq, err := strconv.Atoi(m[1])
+		if err != nil {
+			continue
+		}
+		seqs = append(seqs, seq)
+	}
+	sort.Ints(seqs)
+
+	for _, seq := range seqs {
+		path := e.segmentPath(seq)
+		entries, err := readSegmentEntries(path)
+		if err != nil {
+			log.Printf("[Emitter] Failed to read segment %s, skipping: %v", path, err)
+			continue
+		}
+		if len(entries) == 0 {
+			os.Remove(path)
+			continue
+		}
+		e.segments = append(e.segments, &segment{seq: seq, path: path, entries: entries})
+		log.Printf("[Emitter] Replaying %d unacked event(s) from %s", len(entries), path)
+	}
+
+	if len(seqs) > 0 {
+		e.nextSeq = seqs[len(seqs)-1] + 1
+	}
+	return nil
+}
+
+func readSegmentEntries(path string) ([][]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries [][]byte
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(string(line))
+		if err != nil {
+			log.Printf("[Emitter] Skipping corrupt WAL line in %s: %v", path, err)
+			continue
+		}
+		entries = append(entries, decoded)
+	}
+	return entries, scanner.Err()
+}
+
+func (e *BufferedEmitter) segmentPath(seq int) string {
+	return filepath.Join(e.config.Dir, fmt.Sprintf("segment-%08d.wal", seq))
+}
+
+// Start launches the background delivery loop in its own goroutine and
+// returns immediately. The loop runs until ctx is cancelled or Close is
+// called.
+func (e *BufferedEmitter) Start(ctx context.Context) {
+	e.ctx, e.cancel = context.WithCancel(ctx)
+	e.wg.Add(1)
+	go e.run()
+}
+
+// Close stops the delivery loop and fsyncs the active segment.
+func (e *BufferedEmitter) Close() error {
+	if e.cancel != nil {
+		e.cancel()
+	}
+	e.mu.Lock()
+	e.notEmpty.Broadcast()
+	e.notFull.Broadcast()
+	e.mu.Unlock()
+	e.wg.Wait()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.closeActiveLocked()
+}
+
+// Emit durably enqueues data for delivery to the webhook. It returns once
+// the entry has been written to the WAL (not once it has been delivered).
+func (e *BufferedEmitter) Emit(data []byte) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for e.pendingLocked() >= e.config.RingSize {
+		if e.config.Backpressure == BackpressureDropOldest {
+			e.dropOldestLocked()
+			break
+		}
+		// Block until a slot frees up (or the emitter is closed).
+		if e.ctx != nil {
+			select {
+			case <-e.ctx.Done():
+				return fmt.Errorf("emitter: closed")
+			default:
+			}
+		}
+		e.notFull.Wait()
+	}
+
+	if err := e.appendLocked(data); err != nil {
+		return err
+	}
+	e.notEmpty.Broadcast()
+	return nil
+}
+
+// Metrics returns a snapshot of queue health.
+func (e *BufferedEmitter) Metrics() Metrics {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return Metrics{
+		Pending: e.pendingLocked(),
+		Dropped: e.dropped,
+		Acked:   e.acked,
+	}
+}
+
+func (e *BufferedEmitter) pendingLocked() int {
+	total := 0
+	for _, s := range e.segments {
+		total += s.pending()
+	}
+	return total
+}
+
+// dropOldestLocked discards the single oldest unacked entry to make room.
+// Must be called with mu held.
+func (e *BufferedEmitter) dropOldestLocked() {
+	for _, s := range e.segments {
+		if s.pending() > 0 {
+			s.acked++
+			e.dropped++
+			e.reclaimSegmentLocked(s)
+			return
+		}
+	}
+}
+
+// reclaimSegmentLocked deletes a fully-resolved, non-active segment file and
+// drops its bookkeeping. Must be called with mu held.
+func (e *BufferedEmitter) reclaimSegmentLocked(s *segment) {
+	if s.pending() > 0 {
+		return
+	}
+	if len(e.segments) > 0 && e.segments[len(e.segments)-1] == s && e.activeFile != nil {
+		// Still the active (writable) segment; leave the file in place until rotated.
+		return
+	}
+	if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+		log.Printf("[Emitter] Failed to remove acked segment %s: %v", s.path, err)
+	}
+	filtered := e.segments[:0]
+	for _, seg := range e.segments {
+		if seg != s {
+			filtered = append(filtered, seg)
+		}
+	}
+	e.segments = filtered
+	e.notFull.Broadcast()
+}
+
+// appendLocked writes data to the active segment, rotating if needed. Must
+// be called with mu held.
+func (e *BufferedEmitter) appendLocked(data []byte) error {
+	active := e.activeSegmentLocked()
+	if active == nil || len(active.entries) >= e.config.SegmentMaxEntries {
+		if err := e.rotateLocked(); err != nil {
+			return err
+		}
+		active = e.activeSegmentLocked()
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(data)
+	if _, err := e.activeFile.WriteString(encoded + "\n"); err != nil {
+		return fmt.Errorf("emitter: failed to append to WAL: %w", err)
+	}
+
+	// Store a copy; the caller's slice may be reused/mutated.
+	stored := make([]byte, len(data))
+	copy(stored, data)
+	active.entries = append(active.entries, stored)
+	return nil
+}
+
+func (e *BufferedEmitter) activeSegmentLocked() *segment {
+	if len(e.segments) == 0 {
+		return nil
+	}
+	last := e.segments[len(e.segments)-1]
+	if e.activeFile == nil {
+		return nil
+	}
+	return last
+}
+
+// rotateLocked closes the current active segment (if any) and opens a new
+// one, fsyncing the closed segment as it goes.
+func (e *BufferedEmitter) rotateLocked() error {
+	if err := e.closeActiveLocked(); err != nil {
+		return err
+	}
+
+	seq := e.nextSeq
+	e.nextSeq++
+	path := e.segmentPath(seq)
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("emitter: failed to open segment: %w", err)
+	}
+
+	e.activeFile = f
+	e.segments = append(e.segments, &segment{seq: seq, path: path})
+	return nil
+}
+
+// closeActiveLocked fsyncs and closes the active segment file, reclaiming it
+// immediately if every entry in it has already been resolved.
+func (e *BufferedEmitter) closeActiveLocked() error {
+	if e.activeFile == nil {
+		return nil
+	}
+	if err := e.activeFile.Sync(); err != nil {
+		log.Printf("[Emitter] Failed to fsync segment: %v", err)
+	}
+	if err := e.activeFile.Close(); err != nil {
+		log.Printf("[Emitter] Failed to close segment: %v", err)
+	}
+	e.activeFile = nil
+
+	if len(e.segments) > 0 {
+		last := e.segments[len(e.segments)-1]
+		e.reclaimSegmentLocked(last)
+	}
+	return nil
+}
+
+// run is the background delivery loop: it drains segments in order,
+// retrying a failed Send until it succeeds (which naturally replays on
+// webhook reconnect) before advancing.
+func (e *BufferedEmitter) run() {
+	defer e.wg.Done()
+
+	for {
+		e.mu.Lock()
+		for e.pendingLocked() == 0 {
+			select {
+			case <-e.ctx.Done():
+				e.mu.Unlock()
+				return
+			default:
+			}
+			e.notEmpty.Wait()
+			select {
+			case <-e.ctx.Done():
+				e.mu.Unlock()
+				return
+			default:
+			}
+		}
+
+		seg, data := e.nextPendingLocked()
+		e.mu.Unlock()
+
+		if seg == nil {
+			continue
+		}
+
+		if err := e.sender.Send(data); err != nil {
+			log.Printf("[Emitter] Delivery failed, will retry: %v", err)
+			select {
+			case <-e.ctx.Done():
+				return
+			case <-time.After(e.config.RetryInterval):
+			}
+			continue
+		}
+
+		e.mu.Lock()
+		seg.acked++
+		e.acked++
+		e.reclaimSegmentLocked(seg)
+		e.mu.Unlock()
+	}
+}
+
+// nextPendingLocked returns the oldest unacked entry (and its segment)
+// without marking it resolved. Must be called with mu held.
+func (e *BufferedEmitter) nextPendingLocked() (*segment, []byte) {
+	for _, s := range e.segments {
+		if s.pending() > 0 {
+			return s, s.entries[s.acked]
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,95 @@
+package bridge
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sternelee/openclaw-webhook-bridge/internal/openclaw"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/sessions"
+)
+
+// connectDoomedClient gives client a cancelled context so its internal
+// SendRaw/Connect waits return immediately with an error instead of
+// blocking (or, with no context at all, panicking on a nil ctx) - this test
+// only cares where HandleWebhookMessage routes the session, not whether the
+// agent request actually reaches a gateway.
+func connectDoomedClient(t *testing.T, client *openclaw.Client) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = client.Connect(ctx)
+}
+
+func newTestSessionStore(t *testing.T) *sessions.Store {
+	t.Helper()
+	cfg := sessions.DefaultStoreConfig(filepath.Join(t.TempDir(), "sessions.json"))
+	cfg.FlushInterval = time.Millisecond
+	store := sessions.NewStore(cfg)
+	t.Cleanup(store.Close)
+	return store
+}
+
+// TestHandleWebhookMessageCollapsesDMThreadsToOneSession exercises the
+// SessionGroup wiring in HandleWebhookMessage: two DM messages in different
+// threads from the same peer should land in the same session (the group's
+// bare base key), since DM groups always collapse to one conversation (see
+// SessionGroup.ShouldCollapseToMain).
+func TestHandleWebhookMessageCollapsesDMThreadsToOneSession(t *testing.T) {
+	client := newTestClient(t, "main")
+	connectDoomedClient(t, client)
+	b := NewBridge(nil, client)
+	b.SetSessionStore(newTestSessionStore(t))
+
+	send := func(threadID string) {
+		data := []byte(`{"id":"msg-` + threadID + `","content":"hi","peerKind":"dm","peerId":"user1","threadId":"` + threadID + `"}`)
+		// SendAgentRequest fails (no live connection) - that's expected and
+		// irrelevant to what this test checks: where the session landed.
+		_ = b.HandleWebhookMessage(data)
+	}
+	send("t1")
+	send("t2")
+
+	group, err := b.sessionStore.LoadGroup(sessions.WebhookSessionParams{AgentID: "main", PeerKind: "dm", PeerID: "user1"})
+	if err != nil {
+		t.Fatalf("LoadGroup() error = %v", err)
+	}
+	members := group.Members()
+	if len(members) != 1 {
+		t.Fatalf("Members() = %v, want exactly 1 (both threads collapsed to the base key)", members)
+	}
+
+	entry, err := b.sessionStore.GetEntry(group.BaseKey())
+	if err != nil {
+		t.Fatalf("GetEntry(BaseKey()) error = %v", err)
+	}
+	if entry == nil {
+		t.Fatalf("GetEntry(BaseKey()) = nil, want the collapsed session")
+	}
+}
+
+// TestHandleWebhookMessageKeepsGroupTopicsSeparate exercises the opposite
+// case: a group/channel peer's topics must NOT collapse, since
+// ShouldCollapseToMain only collapses DMs.
+func TestHandleWebhookMessageKeepsGroupTopicsSeparate(t *testing.T) {
+	client := newTestClient(t, "main")
+	connectDoomedClient(t, client)
+	b := NewBridge(nil, client)
+	b.SetSessionStore(newTestSessionStore(t))
+
+	send := func(topicID string) {
+		data := []byte(`{"id":"msg-` + topicID + `","content":"hi","peerKind":"group","peerId":"room1","topicId":"` + topicID + `"}`)
+		_ = b.HandleWebhookMessage(data)
+	}
+	send("t1")
+	send("t2")
+
+	group, err := b.sessionStore.LoadGroup(sessions.WebhookSessionParams{AgentID: "main", PeerKind: "group", PeerID: "room1"})
+	if err != nil {
+		t.Fatalf("LoadGroup() error = %v", err)
+	}
+	if members := group.Members(); len(members) != 2 {
+		t.Fatalf("Members() = %v, want 2 (one session per topic)", members)
+	}
+}
@@ -0,0 +1,117 @@
+package bridge
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sternelee/openclaw-webhook-bridge/internal/backoff"
+)
+
+// Runner starts and runs a single bridge until ctx is cancelled or it gives
+// up (e.g. its connections fail permanently). Supervisor.Run restarts it
+// with decorrelated-jitter backoff whenever it returns before ctx is done,
+// and recovers a panic the same way, so one misbehaving tenant can't take
+// the whole daemon down.
+type Runner func(ctx context.Context) error
+
+// Status is a point-in-time snapshot of one supervised bridge, used to
+// render the "status" CLI table across every bridge a daemon is running.
+type Status struct {
+	UID          string
+	GatewayUp    bool
+	WebhookUp    bool
+	LastActivity time.Time
+	Restarts     int
+}
+
+// Supervisor runs a fixed set of Runners concurrently under one process,
+// restarting any that panic or return an error without affecting its
+// siblings. It's the multi-bridge analogue of a single cmdRunRelease call.
+type Supervisor struct {
+	mu       sync.Mutex
+	statuses map[string]*Status
+}
+
+// NewSupervisor returns an empty Supervisor.
+func NewSupervisor() *Supervisor {
+	return &Supervisor{statuses: make(map[string]*Status)}
+}
+
+// Run registers uid and blocks, restarting runner (with decorrelated-jitter
+// backoff between attempts) until ctx is cancelled. Intended to be called in
+// its own goroutine, one per bridge.
+func (s *Supervisor) Run(ctx context.Context, uid string, runner Runner) {
+	s.mu.Lock()
+	s.statuses[uid] = &Status{UID: uid}
+	s.mu.Unlock()
+
+	delay := backoff.NewDecorrelated(backoff.DefaultConfig())
+	for {
+		err := s.runOnce(ctx, uid, runner)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Printf("[Supervisor] Bridge %s stopped, restarting: %v", uid, err)
+		}
+
+		s.recordRestart(uid)
+		wait := delay.Next()
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// runOnce invokes runner, converting a panic into an error so the caller's
+// restart loop handles both uniformly.
+func (s *Supervisor) runOnce(ctx context.Context, uid string, runner Runner) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("bridge %s panicked: %v", uid, r)
+		}
+	}()
+	return runner(ctx)
+}
+
+func (s *Supervisor) recordRestart(uid string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if st, ok := s.statuses[uid]; ok {
+		st.Restarts++
+	}
+}
+
+// Touch records uid's current connection state and bumps its last-activity
+// timestamp to now. Runners call this (typically from a periodic ticker) so
+// Status reflects live state.
+func (s *Supervisor) Touch(uid string, gatewayUp, webhookUp bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.statuses[uid]
+	if !ok {
+		st = &Status{UID: uid}
+		s.statuses[uid] = st
+	}
+	st.GatewayUp = gatewayUp
+	st.WebhookUp = webhookUp
+	st.LastActivity = time.Now()
+}
+
+// Status returns a snapshot of every supervised bridge, sorted by UID.
+func (s *Supervisor) Status() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Status, 0, len(s.statuses))
+	for _, st := range s.statuses {
+		out = append(out, *st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].UID < out[j].UID })
+	return out
+}
@@ -0,0 +1,149 @@
+package bridge
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"github.com/sternelee/openclaw-webhook-bridge/internal/openclaw"
+)
+
+// DefaultTenantID is used for the implicit tenant created by the
+// single-agent NewBridge constructor.
+const DefaultTenantID = "default"
+
+// ClientRegistry holds one OpenClaw client per tenant, keyed by tenant ID.
+// It lets a single Bridge process route webhook messages to the right
+// agent/tenant instead of requiring one bridge process per agent.
+type ClientRegistry struct {
+	mu      sync.RWMutex
+	clients map[string]*openclaw.Client
+}
+
+// NewClientRegistry creates an empty client registry.
+func NewClientRegistry() *ClientRegistry {
+	return &ClientRegistry{clients: make(map[string]*openclaw.Client)}
+}
+
+// NewSingleClientRegistry builds a registry with exactly one client
+// registered under DefaultTenantID, for callers that don't need
+// multi-tenancy.
+func NewSingleClientRegistry(client *openclaw.Client) *ClientRegistry {
+	r := NewClientRegistry()
+	r.Register(DefaultTenantID, client)
+	return r
+}
+
+// Register adds or replaces the client for a tenant.
+func (r *ClientRegistry) Register(tenantID string, client *openclaw.Client) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.clients[tenantID] = client
+}
+
+// Get returns the client registered for tenantID.
+func (r *ClientRegistry) Get(tenantID string) (*openclaw.Client, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	client, ok := r.clients[tenantID]
+	return client, ok
+}
+
+// Tenants returns the registered tenant IDs.
+func (r *ClientRegistry) Tenants() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ids := make([]string, 0, len(r.clients))
+	for id := range r.clients {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// Len returns the number of registered tenants.
+func (r *ClientRegistry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.clients)
+}
+
+// TenantResolver picks which tenant a parsed webhook message belongs to.
+type TenantResolver interface {
+	ResolveTenant(data []byte) string
+}
+
+// DefaultTenantResolver inspects a webhook message for an explicit tenantId
+// field, falling back to a peerKind:peerId pair, then DefaultTenantID.
+type DefaultTenantResolver struct{}
+
+// ResolveTenant implements TenantResolver.
+func (DefaultTenantResolver) ResolveTenant(data []byte) string {
+	var msg struct {
+		TenantID string `json:"tenantId"`
+		PeerKind string `json:"peerKind"`
+		PeerID   string `json:"peerId"`
+		ChatType string `json:"chatType"`
+		ChatID   string `json:"chatId"`
+	}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return DefaultTenantID
+	}
+
+	if tenantID := strings.TrimSpace(msg.TenantID); tenantID != "" {
+		return tenantID
+	}
+
+	peerKind := strings.TrimSpace(msg.PeerKind)
+	if peerKind == "" {
+		peerKind = strings.TrimSpace(msg.ChatType)
+	}
+	peerID := strings.TrimSpace(msg.PeerID)
+	if peerID == "" {
+		peerID = strings.TrimSpace(msg.ChatID)
+	}
+	if peerKind != "" && peerID != "" {
+		return peerKind + ":" + peerID
+	}
+
+	return DefaultTenantID
+}
+
+// resolveClient picks the OpenClaw client that should handle data, falling
+// back to the single registered client when resolution is ambiguous and
+// only one tenant is configured.
+func (b *Bridge) resolveClient(data []byte) (tenantID string, client *openclaw.Client, ok bool) {
+	tenantID = b.tenantResolver.ResolveTenant(data)
+	if client, ok = b.registry.Get(tenantID); ok {
+		return tenantID, client, true
+	}
+
+	// Single-tenant deployments shouldn't need exact resolver matches.
+	if tenants := b.registry.Tenants(); len(tenants) == 1 {
+		tenantID = tenants[0]
+		client, ok = b.registry.Get(tenantID)
+		return tenantID, client, ok
+	}
+
+	return tenantID, nil, false
+}
+
+// tagWithTenant adds a "tenantId" field to a JSON payload so multi-tenant
+// webhook consumers can tell events from different agents apart. It returns
+// data unchanged if it isn't a JSON object.
+func tagWithTenant(data []byte, tenantID string) []byte {
+	if data == nil {
+		return nil
+	}
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return data
+	}
+	obj["tenantId"] = tenantID
+
+	tagged, err := json.Marshal(obj)
+	if err != nil {
+		return data
+	}
+	return tagged
+}
@@ -0,0 +1,99 @@
+package bridge
+
+import (
+	"testing"
+
+	"github.com/sternelee/openclaw-webhook-bridge/internal/openclaw"
+)
+
+func newTestClient(t *testing.T, agentID string) *openclaw.Client {
+	t.Helper()
+	client, err := openclaw.NewClient("ws://127.0.0.1:0", "token", agentID, nil, nil, nil)
+	if err != nil {
+		t.Fatalf("openclaw.NewClient(%q) error = %v", agentID, err)
+	}
+	return client
+}
+
+func TestClientRegistryRegisterAndGet(t *testing.T) {
+	registry := NewClientRegistry()
+	if registry.Len() != 0 {
+		t.Fatalf("new registry Len() = %d, want 0", registry.Len())
+	}
+
+	acme := newTestClient(t, "acme")
+	registry.Register("acme", acme)
+	if got, ok := registry.Get("acme"); !ok || got != acme {
+		t.Fatalf("Get(acme) = %v, %v, want %v, true", got, ok, acme)
+	}
+	if _, ok := registry.Get("missing"); ok {
+		t.Fatalf("Get(missing) ok = true, want false")
+	}
+	if registry.Len() != 1 {
+		t.Fatalf("Len() = %d, want 1", registry.Len())
+	}
+}
+
+func TestNewSingleClientRegistryUsesDefaultTenantID(t *testing.T) {
+	client := newTestClient(t, "main")
+	registry := NewSingleClientRegistry(client)
+
+	if got, ok := registry.Get(DefaultTenantID); !ok || got != client {
+		t.Fatalf("Get(DefaultTenantID) = %v, %v, want %v, true", got, ok, client)
+	}
+	if got := registry.Tenants(); len(got) != 1 || got[0] != DefaultTenantID {
+		t.Fatalf("Tenants() = %v, want [%s]", got, DefaultTenantID)
+	}
+}
+
+func TestDefaultTenantResolverResolvesExplicitTenantID(t *testing.T) {
+	resolver := DefaultTenantResolver{}
+
+	if got := resolver.ResolveTenant([]byte(`{"tenantId":"acme"}`)); got != "acme" {
+		t.Fatalf("ResolveTenant(explicit tenantId) = %q, want acme", got)
+	}
+	if got := resolver.ResolveTenant([]byte(`{"peerKind":"whatsapp","peerId":"123"}`)); got != "whatsapp:123" {
+		t.Fatalf("ResolveTenant(peerKind/peerId) = %q, want whatsapp:123", got)
+	}
+	if got := resolver.ResolveTenant([]byte(`{"chatType":"slack","chatId":"c1"}`)); got != "slack:c1" {
+		t.Fatalf("ResolveTenant(chatType/chatId fallback) = %q, want slack:c1", got)
+	}
+	if got := resolver.ResolveTenant([]byte(`{}`)); got != DefaultTenantID {
+		t.Fatalf("ResolveTenant(no identifying fields) = %q, want %s", got, DefaultTenantID)
+	}
+	if got := resolver.ResolveTenant([]byte(`not json`)); got != DefaultTenantID {
+		t.Fatalf("ResolveTenant(invalid json) = %q, want %s", got, DefaultTenantID)
+	}
+}
+
+func TestResolveClientMultiTenant(t *testing.T) {
+	acme := newTestClient(t, "acme-agent")
+	globex := newTestClient(t, "globex-agent")
+	registry := NewClientRegistry()
+	registry.Register("acme", acme)
+	registry.Register("globex", globex)
+
+	b := NewMultiTenantBridge(nil, registry, DefaultTenantResolver{})
+
+	tenantID, client, ok := b.resolveClient([]byte(`{"tenantId":"globex"}`))
+	if !ok || tenantID != "globex" || client != globex {
+		t.Fatalf("resolveClient(globex) = %q, %v, %v, want globex, %v, true", tenantID, client, ok, globex)
+	}
+
+	if _, _, ok := b.resolveClient([]byte(`{}`)); ok {
+		t.Fatalf("resolveClient(no tenant hint) ok = true with >1 tenant registered, want false")
+	}
+}
+
+func TestResolveClientSingleTenantFallsBackOnAmbiguousResolve(t *testing.T) {
+	client := newTestClient(t, "main")
+	b := NewBridge(nil, client)
+
+	// DefaultTenantResolver can't identify a tenant from this payload, but
+	// with exactly one tenant registered resolveClient should still route
+	// to it rather than drop the message.
+	tenantID, got, ok := b.resolveClient([]byte(`{}`))
+	if !ok || tenantID != DefaultTenantID || got != client {
+		t.Fatalf("resolveClient({}) = %q, %v, %v, want %s, %v, true", tenantID, got, ok, DefaultTenantID, client)
+	}
+}
@@ -0,0 +1,83 @@
+package bridge
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/sternelee/openclaw-webhook-bridge/internal/metrics"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/sessions"
+)
+
+func TestWatchSessionChangesUpdatesSessionActiveGauge(t *testing.T) {
+	cfg := sessions.DefaultStoreConfig(filepath.Join(t.TempDir(), "sessions.json"))
+	cfg.FlushInterval = time.Millisecond
+	store := sessions.NewStore(cfg)
+	t.Cleanup(store.Close)
+
+	client := newTestClient(t, "main")
+	b := NewBridge(nil, client)
+	b.SetSessionStore(store)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	if err := b.WatchSessionChanges(ctx); err != nil {
+		t.Fatalf("WatchSessionChanges() error = %v", err)
+	}
+
+	waitForGauge := func(want float64) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			if testutil.ToFloat64(metrics.SessionActive) == want {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+		t.Fatalf("SessionActive gauge = %v, want %v", testutil.ToFloat64(metrics.SessionActive), want)
+	}
+	waitForGauge(0)
+
+	if _, err := store.UpdateEntry("agent:main:webhook:1", func(*sessions.SessionEntry) (*sessions.SessionEntry, error) {
+		return &sessions.SessionEntry{SessionID: "sess_1"}, nil
+	}); err != nil {
+		t.Fatalf("UpdateEntry() error = %v", err)
+	}
+	waitForGauge(1)
+
+	if err := store.Delete("agent:main:webhook:1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	waitForGauge(0)
+}
+
+func TestObserveSessionActiveStopsPollingOnceWatching(t *testing.T) {
+	cfg := sessions.DefaultStoreConfig(filepath.Join(t.TempDir(), "sessions.json"))
+	cfg.FlushInterval = time.Millisecond
+	store := sessions.NewStore(cfg)
+	t.Cleanup(store.Close)
+
+	client := newTestClient(t, "main")
+	b := NewBridge(nil, client)
+	b.SetSessionStore(store)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	if err := b.WatchSessionChanges(ctx); err != nil {
+		t.Fatalf("WatchSessionChanges() error = %v", err)
+	}
+	if !b.watchingSessions.Load() {
+		t.Fatalf("watchingSessions = false after a successful WatchSessionChanges")
+	}
+
+	// observeSessionActive must be a no-op now - it would otherwise
+	// re-derive the gauge from a stale Load() snapshot.
+	metrics.SessionActive.Set(42)
+	b.observeSessionActive()
+	if got := testutil.ToFloat64(metrics.SessionActive); got != 42 {
+		t.Fatalf("SessionActive gauge = %v after observeSessionActive with watch active, want unchanged 42", got)
+	}
+}
@@ -3,29 +3,65 @@ package commands
 import (
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/sternelee/openclaw-webhook-bridge/internal/metrics"
 	"github.com/sternelee/openclaw-webhook-bridge/internal/openclaw"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/sessions"
 )
 
-// CommandHandler handles slash commands from webhook messages
-type CommandHandler struct {
-	gatewayClient GatewayClient
-}
+// skillCacheTTL bounds how long a /skill (no args) reply serves ListSkills
+// results from cache before re-querying the Gateway.
+const skillCacheTTL = 30 * time.Second
 
-// GatewayClient interface for OpenClaw Gateway communication
+// GatewayClient is the OpenClaw Gateway surface the command handler needs.
 type GatewayClient interface {
 	ListSkills() ([]openclaw.SkillInfo, error)
 	ListCommands() ([]openclaw.CommandInfo, error)
 	SendApproval(requestID string, approved bool) error
+	IsConnected() bool
+}
+
+// CommandHandler handles slash commands from webhook messages. Commands
+// themselves live in a CommandRegistry (see registry.go); this type wires
+// the registry up to the Gateway client and session store and owns the
+// Gateway-backed skill cache.
+type CommandHandler struct {
+	gatewayClient GatewayClient
+	sessionStore  *sessions.Store
+	webhookStatus func() bool
+	startedAt     time.Time
+
+	registry *CommandRegistry
+
+	skillsMu    sync.Mutex
+	skillsCache []openclaw.SkillInfo
+	skillsAt    time.Time
 }
 
-// NewCommandHandler creates a new command handler
-func NewCommandHandler(gateway GatewayClient) *CommandHandler {
-	return &CommandHandler{
+// NewCommandHandler creates a command handler with the built-in command set
+// registered. sessionStore and webhookStatus may be nil; /status and the
+// session-patching commands degrade gracefully when they are.
+func NewCommandHandler(gateway GatewayClient, sessionStore *sessions.Store, webhookStatus func() bool) *CommandHandler {
+	h := &CommandHandler{
 		gatewayClient: gateway,
+		sessionStore:  sessionStore,
+		webhookStatus: webhookStatus,
+		startedAt:     time.Now(),
+		registry:      NewCommandRegistry(),
 	}
+	h.registerBuiltins()
+	return h
+}
+
+// Registry exposes the underlying CommandRegistry so callers (bridge, or
+// future subsystems) can Register additional commands or SetPermission a
+// CanRun hook without the handler needing to know about them.
+func (h *CommandHandler) Registry() *CommandRegistry {
+	return h.registry
 }
 
 // IsCommand checks if a message starts with a slash command
@@ -55,113 +91,131 @@ func ParseCommand(message string) (command string, args string) {
 	return command, args
 }
 
-// HandleCommand processes a slash command and returns a response
-func (h *CommandHandler) HandleCommand(message string) (string, error) {
+// HandleCommand processes a slash command and returns a response. uid and
+// sessionID identify the bridge and conversation the command came from and
+// are attached to every log line as structured fields so a single command
+// invocation can be traced across a multi-tenant bridge's logs.
+func (h *CommandHandler) HandleCommand(message, uid, sessionID string) (string, error) {
 	command, args := ParseCommand(message)
 
-	log.Printf("[Commands] Processing command: /%s args: %s", command, args)
-
-	switch command {
-	case "help":
-		return h.handleHelp()
-	case "commands":
-		return h.handleCommands()
-	case "skill", "skills":
-		return h.handleSkill(args)
-	case "approve":
-		return h.handleApprove(args)
-	default:
-		return "", fmt.Errorf("unknown command: /%s", command)
-	}
-}
-
-// handleHelp returns help information
-func (h *CommandHandler) handleHelp() (string, error) {
-	helpText := `**Available Commands:**
+	slog.Default().Info("processing command",
+		"uid", uid, "session_id", sessionID, "command", command, "args", args)
 
-🔹 **/help** - Show this help message
-🔹 **/commands** - List all available commands
-🔹 **/skill [name]** - List skills or run a specific skill
-🔹 **/approve [id]** - Approve or deny pending requests
+	response, err := h.registry.Dispatch(&Context{UID: uid, SessionID: sessionID}, command, args)
 
-💡 Use /commands to see the full command list
-💡 Use /skill to see all available skills`
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+	metrics.CommandInvocationsTotal.WithLabelValues(command, result).Inc()
 
-	return helpText, nil
+	return response.Content, err
 }
 
-// handleCommands lists all available commands
-func (h *CommandHandler) handleCommands() (string, error) {
-	// Return static command list instead of querying Gateway
-	var response strings.Builder
-	response.WriteString("**Available Commands:**\n\n")
-
-	response.WriteString("**📊 Status**\n")
-	response.WriteString("  /help - Show this help message\n")
-	response.WriteString("  /commands - List all available commands\n")
-	response.WriteString("  /status - Show current connection status\n\n")
+// registerBuiltins installs the handlers this bridge ships with. Nothing
+// here is special-cased by HandleCommand - additional commands can be added
+// the same way via h.registry.Register.
+func (h *CommandHandler) registerBuiltins() {
+	h.registry.Register("help", nil, "Show this help message", h.handleHelp)
+	h.registry.Register("commands", nil, "List all available commands", h.handleCommands)
+	h.registry.Register("skill", []string{"skills"}, "List skills, or run /skill <name> [args]", h.handleSkill)
+	h.registry.Register("approve", nil, "Approve or deny a pending request: /approve <id> [yes|no]", h.handleApprove)
+	h.registry.Register("status", nil, "Show session count, uptime, and connection state", h.handleStatus)
+	h.registry.Register("model", nil, "Override the model for this session: /model <name>", h.handleModel)
+	h.registry.Register("provider", nil, "Override the provider for this session: /provider <name>", h.handleProvider)
+	h.registry.Register("thinking", nil, "Set the thinking level for this session: /thinking <level>", h.handleThinking)
+	h.registry.Register("reset", []string{"new"}, "Start a fresh session", h.handleReset)
+}
 
-	response.WriteString("**🛠️ Tools**\n")
-	response.WriteString("  /skill - List all available skills\n")
-	response.WriteString("  /skill <name> [args] - Run a specific skill\n\n")
+// handleHelp returns help information
+func (h *CommandHandler) handleHelp(ctx *Context, args string) (Response, error) {
+	var b strings.Builder
+	b.WriteString("**Available Commands:**\n\n")
+	for _, spec := range h.registry.List() {
+		b.WriteString(fmt.Sprintf("🔹 **/%s** - %s\n", spec.Name, spec.Help))
+	}
+	b.WriteString("\n💡 Use /commands to also see command aliases\n")
+	b.WriteString("💡 Use /skill to see all available skills")
+	return Response{Content: b.String()}, nil
+}
 
-	response.WriteString("**⚙️ Management**\n")
-	response.WriteString("  /approve <id> [yes|no] - Approve or deny execution requests\n\n")
+// handleCommands lists all available commands from the registry
+func (h *CommandHandler) handleCommands(ctx *Context, args string) (Response, error) {
+	var b strings.Builder
+	b.WriteString("**Available Commands:**\n\n")
+	for _, spec := range h.registry.List() {
+		aliasSuffix := ""
+		if len(spec.Aliases) > 0 {
+			aliasSuffix = fmt.Sprintf(" (aliases: %s)", strings.Join(prefixAll(spec.Aliases, "/"), ", "))
+		}
+		b.WriteString(fmt.Sprintf("  /%s%s - %s\n", spec.Name, aliasSuffix, spec.Help))
+	}
+	return Response{Content: b.String()}, nil
+}
 
-	return response.String(), nil
+func prefixAll(items []string, prefix string) []string {
+	out := make([]string, len(items))
+	for i, item := range items {
+		out[i] = prefix + item
+	}
+	return out
 }
 
-// handleSkill lists skills or runs a specific skill
-func (h *CommandHandler) handleSkill(args string) (string, error) {
-	// If no args provided, list all skills
+// handleSkill lists skills (via the Gateway, cached) or forwards a specific
+// skill invocation to the Gateway.
+func (h *CommandHandler) handleSkill(ctx *Context, args string) (Response, error) {
 	if args == "" {
 		return h.listSkills()
 	}
 
-	// If args provided, return a message indicating the skill request will be forwarded
-	// The actual execution should be handled by forwarding to OpenClaw Gateway
-	return "", fmt.Errorf("FORWARD_TO_GATEWAY:/skill %s", args)
+	// The actual execution is handled by forwarding to the OpenClaw Gateway.
+	return Response{}, fmt.Errorf("FORWARD_TO_GATEWAY:/skill %s", args)
 }
 
-// listSkills returns a list of available skills
-func (h *CommandHandler) listSkills() (string, error) {
-	// Return static skill list for common OpenClaw skills
-	var response strings.Builder
-	response.WriteString("**Available Skills:**\n\n")
-
-	// Common OpenClaw skills
-	skills := []struct {
-		name        string
-		description string
-	}{
-		{"web-search", "Search the web for information"},
-		{"read-file", "Read and analyze file contents"},
-		{"write-file", "Create or modify files"},
-		{"bash", "Execute shell commands"},
-		{"ask-human", "Ask the user for clarification"},
+// listSkills returns the Gateway's current skill list, serving from cache
+// when it's younger than skillCacheTTL.
+func (h *CommandHandler) listSkills() (Response, error) {
+	h.skillsMu.Lock()
+	defer h.skillsMu.Unlock()
+
+	if h.skillsCache == nil || time.Since(h.skillsAt) > skillCacheTTL {
+		if h.gatewayClient == nil {
+			return Response{}, fmt.Errorf("no gateway client configured")
+		}
+		skills, err := h.gatewayClient.ListSkills()
+		if err != nil {
+			return Response{}, fmt.Errorf("failed to list skills: %w", err)
+		}
+		h.skillsCache = skills
+		h.skillsAt = time.Now()
 	}
 
-	for _, skill := range skills {
-		response.WriteString(fmt.Sprintf("🔧 **%s**\n", skill.name))
-		response.WriteString(fmt.Sprintf("   %s\n", skill.description))
-		response.WriteString(fmt.Sprintf("   Usage: `/skill %s [args]`\n\n", skill.name))
+	var b strings.Builder
+	b.WriteString("**Available Skills:**\n\n")
+	if len(h.skillsCache) == 0 {
+		b.WriteString("No skills are currently available.\n")
 	}
+	for _, skill := range h.skillsCache {
+		b.WriteString(fmt.Sprintf("🔧 **%s**\n", skill.Name))
+		if skill.Description != "" {
+			b.WriteString(fmt.Sprintf("   %s\n", skill.Description))
+		}
+		b.WriteString(fmt.Sprintf("   Usage: `/skill %s [args]`\n\n", skill.Name))
+	}
+	b.WriteString("💡 **Tip**: Use `/skill <name> <args>` to run a skill\n")
 
-	response.WriteString("💡 **Tip**: Use `/skill <name> <args>` to run a skill\n")
-	response.WriteString("   Example: `/skill web-search OpenClaw AI`\n")
-
-	return response.String(), nil
+	return Response{Content: b.String()}, nil
 }
 
 // handleApprove processes approval requests
-func (h *CommandHandler) handleApprove(args string) (string, error) {
+func (h *CommandHandler) handleApprove(ctx *Context, args string) (Response, error) {
 	if args == "" {
-		return "Usage: /approve <request-id> [yes|no]", nil
+		return Response{Content: "Usage: /approve <request-id> [yes|no]"}, nil
 	}
 
 	parts := strings.Fields(args)
 	if len(parts) < 1 {
-		return "Usage: /approve <request-id> [yes|no]", nil
+		return Response{Content: "Usage: /approve <request-id> [yes|no]"}, nil
 	}
 
 	requestID := parts[0]
@@ -172,10 +226,14 @@ func (h *CommandHandler) handleApprove(args string) (string, error) {
 		approved = decision == "yes" || decision == "y" || decision == "approve"
 	}
 
+	if h.gatewayClient == nil {
+		return Response{}, fmt.Errorf("no gateway client configured")
+	}
+
 	err := h.gatewayClient.SendApproval(requestID, approved)
 	if err != nil {
-		log.Printf("[Commands] Failed to send approval: %v", err)
-		return "", fmt.Errorf("failed to send approval: %w", err)
+		slog.Default().Error("failed to send approval", "request_id", requestID, "error", err)
+		return Response{}, fmt.Errorf("failed to send approval: %w", err)
 	}
 
 	status := "approved"
@@ -183,7 +241,104 @@ func (h *CommandHandler) handleApprove(args string) (string, error) {
 		status = "denied"
 	}
 
-	return fmt.Sprintf("Request %s has been %s", requestID, status), nil
+	return Response{Content: fmt.Sprintf("Request %s has been %s", requestID, status)}, nil
+}
+
+// handleStatus reports session count, uptime, and connection state.
+func (h *CommandHandler) handleStatus(ctx *Context, args string) (Response, error) {
+	sessionCount := 0
+	if h.sessionStore != nil {
+		if view, err := h.sessionStore.LoadReadonly(); err == nil {
+			sessionCount = view.Count()
+		}
+	}
+
+	gatewayUp := h.gatewayClient != nil && h.gatewayClient.IsConnected()
+	webhookUp := h.webhookStatus != nil && h.webhookStatus()
+
+	text := fmt.Sprintf(
+		"**Bridge Status**\n\nSessions: %d\nUptime: %s\nGateway: %s\nWebhook: %s",
+		sessionCount,
+		time.Since(h.startedAt).Round(time.Second),
+		connectionLabel(gatewayUp),
+		connectionLabel(webhookUp),
+	)
+	return Response{Content: text}, nil
+}
+
+func connectionLabel(up bool) string {
+	if up {
+		return "connected"
+	}
+	return "disconnected"
+}
+
+// handleModel patches the session's ModelOverride.
+func (h *CommandHandler) handleModel(ctx *Context, args string) (Response, error) {
+	if args == "" {
+		return Response{Content: "Usage: /model <name>"}, nil
+	}
+	if err := h.patchSession(ctx, &sessions.SessionEntry{ModelOverride: args}); err != nil {
+		return Response{}, err
+	}
+	return Response{Content: fmt.Sprintf("Model overridden to %s for this session", args)}, nil
+}
+
+// handleProvider patches the session's ProviderOverride.
+func (h *CommandHandler) handleProvider(ctx *Context, args string) (Response, error) {
+	if args == "" {
+		return Response{Content: "Usage: /provider <name>"}, nil
+	}
+	if err := h.patchSession(ctx, &sessions.SessionEntry{ProviderOverride: args}); err != nil {
+		return Response{}, err
+	}
+	return Response{Content: fmt.Sprintf("Provider overridden to %s for this session", args)}, nil
+}
+
+// handleThinking patches the session's ThinkingLevel.
+func (h *CommandHandler) handleThinking(ctx *Context, args string) (Response, error) {
+	if args == "" {
+		return Response{Content: "Usage: /thinking <level>"}, nil
+	}
+	if err := h.patchSession(ctx, &sessions.SessionEntry{ThinkingLevel: args}); err != nil {
+		return Response{}, err
+	}
+	return Response{Content: fmt.Sprintf("Thinking level set to %s for this session", args)}, nil
+}
+
+// patchSession merges patch into ctx.SessionID's entry via the session store.
+func (h *CommandHandler) patchSession(ctx *Context, patch *sessions.SessionEntry) error {
+	if h.sessionStore == nil {
+		return fmt.Errorf("no session store configured")
+	}
+	if ctx.SessionID == "" {
+		return fmt.Errorf("no active session")
+	}
+	_, err := h.sessionStore.Patch(ctx.SessionID, patch)
+	return err
+}
+
+// handleReset starts a fresh session for ctx.SessionID, same as the
+// bridge's /new and /reset trigger phrases.
+func (h *CommandHandler) handleReset(ctx *Context, args string) (Response, error) {
+	if h.sessionStore == nil {
+		return Response{}, fmt.Errorf("no session store configured")
+	}
+	if ctx.SessionID == "" {
+		return Response{}, fmt.Errorf("no active session")
+	}
+
+	_, err := h.sessionStore.UpdateEntry(ctx.SessionID, func(existing *sessions.SessionEntry) (*sessions.SessionEntry, error) {
+		return &sessions.SessionEntry{
+			SessionID: sessions.GenerateSessionID(),
+			UpdatedAt: time.Now().UnixMilli(),
+		}, nil
+	})
+	if err != nil {
+		return Response{}, fmt.Errorf("failed to reset session: %w", err)
+	}
+
+	return Response{Content: "Session has been reset"}, nil
 }
 
 // FormatCommandResponse wraps a command response in the webhook message format
@@ -0,0 +1,122 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Context carries the per-invocation state a handler needs: who issued the
+// command and which session it was issued in. It's passed by pointer so
+// handlers that only need a subset of fields don't force callers to thread
+// extra parameters through.
+type Context struct {
+	UID       string
+	SessionID string
+}
+
+// Response is what a registered handler returns; Content is rendered back
+// to the user as the command's reply text.
+type Response struct {
+	Content string
+}
+
+// HandlerFunc implements one slash command.
+type HandlerFunc func(ctx *Context, args string) (Response, error)
+
+// CommandSpec is one entry in a CommandRegistry.
+type CommandSpec struct {
+	Name    string
+	Aliases []string
+	Help    string
+	Handler HandlerFunc
+}
+
+// PermissionFunc decides whether uid may run cmd (the canonical command
+// name, not an alias). A nil PermissionFunc allows everything.
+type PermissionFunc func(uid, cmd string) bool
+
+// CommandRegistry maps command names (and aliases) to handlers, so new
+// commands can be added by calling Register instead of editing a switch
+// statement. It's safe for concurrent use.
+type CommandRegistry struct {
+	mu         sync.RWMutex
+	byName     map[string]*CommandSpec // canonical name and every alias -> spec
+	order      []*CommandSpec          // registration order, for listing
+	permission PermissionFunc
+}
+
+// NewCommandRegistry returns an empty registry.
+func NewCommandRegistry() *CommandRegistry {
+	return &CommandRegistry{
+		byName: make(map[string]*CommandSpec),
+	}
+}
+
+// Register adds a command under name and every alias in aliases. Registering
+// a name or alias that's already taken makes Lookup and Dispatch resolve it
+// to the new entry; the earlier entry is not removed and still appears in
+// List.
+func (r *CommandRegistry) Register(name string, aliases []string, help string, handler HandlerFunc) {
+	spec := &CommandSpec{Name: name, Aliases: aliases, Help: help, Handler: handler}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.byName[name] = spec
+	for _, alias := range aliases {
+		r.byName[alias] = spec
+	}
+	r.order = append(r.order, spec)
+}
+
+// SetPermission installs the hook CanRun and Dispatch use to gate commands
+// per UID. Pass nil to allow everything (the default).
+func (r *CommandRegistry) SetPermission(fn PermissionFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.permission = fn
+}
+
+// CanRun reports whether uid is allowed to run cmd.
+func (r *CommandRegistry) CanRun(uid, cmd string) bool {
+	r.mu.RLock()
+	fn := r.permission
+	r.mu.RUnlock()
+	if fn == nil {
+		return true
+	}
+	return fn(uid, cmd)
+}
+
+// Lookup returns the spec registered for name (a canonical name or alias).
+func (r *CommandRegistry) Lookup(name string) (*CommandSpec, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	spec, ok := r.byName[name]
+	return spec, ok
+}
+
+// List returns every registered command sorted by name, one entry per
+// CommandSpec (not per alias).
+func (r *CommandRegistry) List() []*CommandSpec {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]*CommandSpec, len(r.order))
+	copy(out, r.order)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Dispatch looks up command, checks CanRun, and invokes its handler with
+// args. Returns an error if the command is unknown or not permitted for
+// ctx.UID.
+func (r *CommandRegistry) Dispatch(ctx *Context, command, args string) (Response, error) {
+	spec, ok := r.Lookup(command)
+	if !ok {
+		return Response{}, fmt.Errorf("unknown command: /%s", command)
+	}
+	if !r.CanRun(ctx.UID, spec.Name) {
+		return Response{}, fmt.Errorf("command /%s is not permitted for this user", spec.Name)
+	}
+	return spec.Handler(ctx, args)
+}
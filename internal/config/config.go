@@ -3,10 +3,13 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/webhook"
 )
 
 // Config holds all configuration for the bridge
@@ -15,9 +18,129 @@ type Config struct {
 	OpenClaw   OpenClawConfig
 	UID        string // Unique ID for this bridge instance
 
+	// WebhookTLS configures wss:// and optional mTLS for the webhook
+	// connection. Nil means plain ws:// (or whatever scheme WebhookURL
+	// already specifies).
+	WebhookTLS *TLSConfig
+
+	// WebhookTargets fans OpenClaw events out to additional downstream HTTP
+	// sinks alongside the primary WebSocket channel (see
+	// webhook.FanoutSender). Empty means no additional targets.
+	WebhookTargets []webhook.TargetConfig
+
 	// Session configuration
 	SessionStorePath string // Path to session store JSON file
 	SessionScope     string // Session scope: "per-sender" or "global"
+
+	// SessionStore selects the sessions.Store backend. Driver is one of
+	// "file" (default), "bolt", "redis", or "etcd"; DSN is backend-specific
+	// (a bbolt file path for "bolt", a host:port address for "redis", an
+	// etcd v3 JSON/gRPC-gateway base URL for "etcd") and ignored for
+	// "file", which always uses SessionStorePath.
+	SessionStore SessionStoreConfig
+
+	// Idempotency configuration for deduping retried webhook deliveries
+	IdempotencyTTLSeconds int // How long a message ID is remembered
+	IdempotencyMaxEntries int // Max dedup entries kept in memory
+
+	// ResetTriggers configures the session reset matcher, keyed by locale
+	// tag (see sessions.ResetTriggerConfig.Phrases). Empty uses
+	// sessions.DefaultResetTriggerConfig().
+	ResetTriggers       map[string][]string
+	ResetTriggerRegexes []string
+	ResetTriggerPrefix  bool // PrefixOnly: trigger may just lead the message
+
+	// MetricsPort, when non-zero, starts a Prometheus /metrics and JSON
+	// /status HTTP server (see internal/metrics) on 127.0.0.1:MetricsPort.
+	MetricsPort int
+	// MetricsToken, when set, is the bearer token required to hit /metrics
+	// or /status. Leave unset only on a host nothing else can reach.
+	MetricsToken string
+
+	// PairingPort, when non-zero, starts the POST /pair/redeem and
+	// /pair/mint endpoints (see internal/pairing) on PairingBindAddr:PairingPort.
+	PairingPort int
+	// PairingBindAddr is the address the pairing HTTP server listens on.
+	// Defaults to "127.0.0.1", but the redeeming mapp device is a separate
+	// host from the bridge by design (that's the whole point of shipping it
+	// only a token, not the bridge's own network details) - operators
+	// exposing pairing to real devices need to set this to a LAN-reachable
+	// address (or "0.0.0.0" behind their own firewall/reverse proxy).
+	PairingBindAddr string
+
+	// LogLevel is one of "debug", "info" (default), "warn", or "error".
+	LogLevel string
+	// LogFormat is "text" (default) or "json".
+	LogFormat string
+	// LogPath, when set, writes logs to a rotating file at this path
+	// instead of stderr (see internal/logging). Release mode defaults this
+	// to a file under config.Dir() instead of discarding output.
+	LogPath string
+	// LogMaxSizeMB is the size in megabytes a log file may reach before
+	// internal/logging rotates it. Defaults to 100.
+	LogMaxSizeMB int
+	// LogMaxBackups is how many rotated log files internal/logging keeps
+	// before deleting the oldest. Defaults to 5.
+	LogMaxBackups int
+
+	// Bridges holds additional bridge entries for running more than one
+	// webhook<->Gateway pairing in a single daemon process. The top-level
+	// WebhookURL/OpenClaw/UID/SessionScope/SessionStorePath fields above
+	// remain the first (or, in the common single-bridge case, only) bridge;
+	// see BridgeConfigs.
+	Bridges []BridgeConfig
+
+	// Tenants holds additional OpenClaw agents a single bridge process
+	// should route to, alongside the top-level OpenClaw agent (registered
+	// under bridge.DefaultTenantID). Unlike Bridges, these share the one
+	// webhook connection and session store; bridge.TenantResolver decides
+	// which tenant an inbound webhook message belongs to. Empty means the
+	// single-tenant case bridge.NewBridge already handles.
+	Tenants []TenantConfig
+}
+
+// TenantConfig is one additional OpenClaw agent a bridge process routes to
+// (see Config.Tenants), registered in the bridge's ClientRegistry under
+// TenantID.
+type TenantConfig struct {
+	TenantID string
+	OpenClaw OpenClawConfig
+}
+
+// BridgeConfig is everything needed to run one webhook<->Gateway bridge.
+// See Config.BridgeConfigs.
+type BridgeConfig struct {
+	UID              string
+	WebhookURL       string
+	WebhookTLS       *TLSConfig
+	OpenClaw         OpenClawConfig
+	SessionScope     string
+	SessionStorePath string
+	SessionStore     SessionStoreConfig
+}
+
+// BridgeConfigs returns every bridge this process should run: cfg's own
+// top-level fields as the first entry, followed by cfg.Bridges. Single-
+// bridge setups (the common case, and the only kind bridge.json without a
+// "bridges" array produces) get back a one-element slice, so callers need
+// only one code path regardless of how many bridges are configured.
+func (cfg *Config) BridgeConfigs() []BridgeConfig {
+	first := BridgeConfig{
+		UID:              cfg.UID,
+		WebhookURL:       cfg.WebhookURL,
+		WebhookTLS:       cfg.WebhookTLS,
+		OpenClaw:         cfg.OpenClaw,
+		SessionScope:     cfg.SessionScope,
+		SessionStorePath: cfg.SessionStorePath,
+		SessionStore:     cfg.SessionStore,
+	}
+	return append([]BridgeConfig{first}, cfg.Bridges...)
+}
+
+// SessionStoreConfig selects and configures the sessions.Store backend.
+type SessionStoreConfig struct {
+	Driver string
+	DSN    string
 }
 
 // OpenClawConfig contains OpenClaw Gateway configuration
@@ -25,6 +148,55 @@ type OpenClawConfig struct {
 	GatewayPort  int
 	GatewayToken string
 	AgentID      string
+
+	// TLS configures wss:// and optional mTLS for the gateway connection.
+	// Nil means plain ws://.
+	TLS *TLSConfig
+}
+
+// TLSConfig configures TLS (and optional mTLS) for a WebSocket connection -
+// shared shape for both the gateway (OpenClawConfig.TLS) and the webhook
+// (Config.WebhookTLS) connections, converted into the relevant client
+// package's own TLSConfig type by the caller that builds that client.
+type TLSConfig struct {
+	// CAFile is a PEM CA bundle used to verify the server certificate,
+	// instead of the system trust store.
+	CAFile string
+	// CertFile and KeyFile are a PEM client certificate/key pair presented
+	// for mTLS. Both must be set together, or neither.
+	CertFile string
+	KeyFile  string
+	// ServerName overrides the SNI/cert-verification hostname, for
+	// connecting by IP or through a tunnel.
+	ServerName string
+	// Insecure disables server certificate verification entirely. Must be
+	// set explicitly - Load logs a startup warning whenever it's on.
+	Insecure bool
+}
+
+// tlsJSON matches the "tls" subobject accepted in both openclaw.json's
+// "gateway" object and bridge.json's top level.
+type tlsJSON struct {
+	CAFile     string `json:"ca_file,omitempty"`
+	CertFile   string `json:"cert_file,omitempty"`
+	KeyFile    string `json:"key_file,omitempty"`
+	ServerName string `json:"server_name,omitempty"`
+	Insecure   bool   `json:"insecure,omitempty"`
+}
+
+// toTLSConfig converts j into a *TLSConfig, or nil if j sets nothing at all
+// (so callers can tell "no tls object" from "an empty one" the same way).
+func (j tlsJSON) toTLSConfig() *TLSConfig {
+	if j == (tlsJSON{}) {
+		return nil
+	}
+	return &TLSConfig{
+		CAFile:     j.CAFile,
+		CertFile:   j.CertFile,
+		KeyFile:    j.KeyFile,
+		ServerName: j.ServerName,
+		Insecure:   j.Insecure,
+	}
 }
 
 // openclawJSON matches ~/.openclaw/openclaw.json (managed by OpenClaw)
@@ -34,14 +206,131 @@ type openclawJSON struct {
 		Auth struct {
 			Token string `json:"token"`
 		} `json:"auth"`
+		TLS tlsJSON `json:"tls,omitempty"`
 	} `json:"gateway"`
 }
 
 // bridgeJSON matches ~/.openclaw/bridge.json
 type bridgeJSON struct {
-	WebhookURL string `json:"webhook_url"`
-	AgentID    string `json:"agent_id,omitempty"`
-	UID        string `json:"uid,omitempty"` // Optional pre-configured UID
+	WebhookURL            string `json:"webhook_url"`
+	AgentID               string `json:"agent_id,omitempty"`
+	UID                   string `json:"uid,omitempty"` // Optional pre-configured UID
+	IdempotencyTTLSeconds int    `json:"idempotency_ttl_seconds,omitempty"`
+	IdempotencyMaxEntries int    `json:"idempotency_max_entries,omitempty"`
+
+	// TLS configures wss:// and optional mTLS for the webhook connection.
+	TLS tlsJSON `json:"tls,omitempty"`
+
+	// Triggers maps a locale tag to literal session-reset phrases, e.g.
+	// {"en": ["new chat", "/reset"], "zh": ["重置"]}.
+	Triggers          map[string][]string `json:"triggers,omitempty"`
+	TriggerRegexes    []string            `json:"trigger_regexes,omitempty"`
+	TriggerPrefixOnly bool                `json:"trigger_prefix_only,omitempty"`
+
+	// SessionStore selects the session store backend; omitted or empty
+	// Driver defaults to "file" (sessions.json next to bridge.json).
+	SessionStore sessionStoreJSON `json:"session_store,omitempty"`
+
+	// Metrics configures the optional Prometheus /metrics endpoint.
+	Metrics metricsJSON `json:"metrics,omitempty"`
+
+	// Pairing configures the optional device-enrollment endpoint.
+	Pairing pairingJSON `json:"pairing,omitempty"`
+
+	// Logging configures internal/logging; all fields are optional.
+	Logging loggingJSON `json:"logging,omitempty"`
+
+	// Bridges runs more than one webhook<->Gateway pairing in a single
+	// daemon process; each entry defaults gateway_port/gateway_token from
+	// openclaw.json the same way the top-level bridge does. Omit this to
+	// run the single bridge described by the top-level fields above.
+	Bridges []bridgeEntryJSON `json:"bridges,omitempty"`
+
+	// Agents routes more than one OpenClaw agent through this single
+	// bridge's one webhook connection (see Config.Tenants), instead of
+	// running a separate bridge process per agent. Omit this to run the
+	// single-tenant case.
+	Agents []agentEntryJSON `json:"agents,omitempty"`
+
+	// Webhooks fans OpenClaw events out to additional downstream HTTP
+	// sinks, alongside the primary WebSocket channel described by
+	// webhook_url above. Omit this if webhook_url is the only destination.
+	Webhooks []webhookTargetJSON `json:"webhooks,omitempty"`
+}
+
+// agentEntryJSON matches one object in bridge.json's "agents" array.
+type agentEntryJSON struct {
+	TenantID     string  `json:"tenant_id"`
+	AgentID      string  `json:"agent_id,omitempty"`
+	GatewayPort  int     `json:"gateway_port,omitempty"`
+	GatewayToken string  `json:"gateway_token,omitempty"`
+	TLS          tlsJSON `json:"tls,omitempty"`
+}
+
+// webhookTargetJSON matches one object in bridge.json's "webhooks" array.
+type webhookTargetJSON struct {
+	Name  string            `json:"name"`
+	URL   string            `json:"url"`
+	Auth  webhookTargetAuth `json:"auth,omitempty"`
+	Types []string          `json:"types,omitempty"`
+	Retry webhookRetryJSON  `json:"retry,omitempty"`
+}
+
+// webhookTargetAuth matches the "auth" object in one webhooks[] entry: a
+// bearer token, or HTTP basic credentials if bearer_token is omitted.
+type webhookTargetAuth struct {
+	BearerToken string `json:"bearer_token,omitempty"`
+	BasicUser   string `json:"basic_user,omitempty"`
+	BasicPass   string `json:"basic_pass,omitempty"`
+}
+
+// webhookRetryJSON matches the "retry" object in one webhooks[] entry.
+// An omitted (or all-zero) object falls back to webhook.DefaultRetryPolicy;
+// otherwise the fields are used as given, zeros included.
+type webhookRetryJSON struct {
+	MaxRetries  int `json:"max_retries,omitempty"`
+	BaseDelayMS int `json:"base_delay_ms,omitempty"`
+	MaxDelayMS  int `json:"max_delay_ms,omitempty"`
+}
+
+// bridgeEntryJSON matches one object in bridge.json's "bridges" array.
+type bridgeEntryJSON struct {
+	UID              string           `json:"uid,omitempty"`
+	WebhookURL       string           `json:"webhook_url"`
+	AgentID          string           `json:"agent_id,omitempty"`
+	GatewayPort      int              `json:"gateway_port,omitempty"`
+	GatewayToken     string           `json:"gateway_token,omitempty"`
+	TLS              tlsJSON          `json:"tls,omitempty"`
+	SessionScope     string           `json:"session_scope,omitempty"`
+	SessionStorePath string           `json:"session_store_path,omitempty"`
+	SessionStore     sessionStoreJSON `json:"session_store,omitempty"`
+}
+
+// metricsJSON matches the "metrics" object in bridge.json.
+type metricsJSON struct {
+	Port  int    `json:"port,omitempty"`
+	Token string `json:"token,omitempty"`
+}
+
+// pairingJSON matches the "pairing" object in bridge.json.
+type pairingJSON struct {
+	Port     int    `json:"port,omitempty"`
+	BindAddr string `json:"bind_addr,omitempty"`
+}
+
+// loggingJSON matches the "logging" object in bridge.json.
+type loggingJSON struct {
+	Level      string `json:"level,omitempty"`
+	Format     string `json:"format,omitempty"`
+	Path       string `json:"path,omitempty"`
+	MaxSizeMB  int    `json:"max_size_mb,omitempty"`
+	MaxBackups int    `json:"max_backups,omitempty"`
+}
+
+// sessionStoreJSON matches the "session_store" object in bridge.json.
+type sessionStoreJSON struct {
+	Driver string `json:"driver,omitempty"` // "file" (default), "bolt", "redis", or "etcd"
+	DSN    string `json:"dsn,omitempty"`    // bolt: db file path; redis: host:port
 }
 
 // Dir returns the config directory path
@@ -130,7 +419,7 @@ func Load() (*Config, error) {
 	cfg := &Config{
 		WebhookURL: brCfg.WebhookURL,
 		OpenClaw: OpenClawConfig{
-			GatewayPort: gwCfg.Gateway.Port,
+			GatewayPort:  gwCfg.Gateway.Port,
 			GatewayToken: gwCfg.Gateway.Auth.Token,
 			AgentID:      "main",
 		},
@@ -143,6 +432,37 @@ func Load() (*Config, error) {
 		cfg.OpenClaw.GatewayPort = 18789
 	}
 
+	cfg.OpenClaw.TLS = gwCfg.Gateway.TLS.toTLSConfig()
+	if cfg.OpenClaw.TLS != nil && cfg.OpenClaw.TLS.Insecure {
+		log.Printf("[Config] WARNING: gateway.tls.insecure is set - server certificate verification is disabled for the gateway connection")
+	}
+
+	cfg.WebhookTLS = brCfg.TLS.toTLSConfig()
+	if cfg.WebhookTLS != nil && cfg.WebhookTLS.Insecure {
+		log.Printf("[Config] WARNING: tls.insecure is set - server certificate verification is disabled for the webhook connection")
+	}
+
+	for i, wh := range brCfg.Webhooks {
+		if wh.URL == "" {
+			return nil, fmt.Errorf("webhooks[%d].url is required in ~/.openclaw/bridge.json", i)
+		}
+		cfg.WebhookTargets = append(cfg.WebhookTargets, webhook.TargetConfig{
+			Name:       wh.Name,
+			URL:        wh.URL,
+			EventTypes: wh.Types,
+			Auth: webhook.TargetAuth{
+				BearerToken: wh.Auth.BearerToken,
+				BasicUser:   wh.Auth.BasicUser,
+				BasicPass:   wh.Auth.BasicPass,
+			},
+			Retry: webhook.RetryPolicy{
+				MaxRetries: wh.Retry.MaxRetries,
+				BaseDelay:  time.Duration(wh.Retry.BaseDelayMS) * time.Millisecond,
+				MaxDelay:   time.Duration(wh.Retry.MaxDelayMS) * time.Millisecond,
+			},
+		})
+	}
+
 	// Generate or set UID
 	if brCfg.UID != "" {
 		cfg.UID = brCfg.UID
@@ -152,10 +472,126 @@ func Load() (*Config, error) {
 
 	// Set session store path
 	cfg.SessionStorePath = filepath.Join(dir, "sessions.json")
+	cfg.SessionStore = SessionStoreConfig{
+		Driver: brCfg.SessionStore.Driver,
+		DSN:    brCfg.SessionStore.DSN,
+	}
+
+	cfg.MetricsPort = brCfg.Metrics.Port
+	cfg.MetricsToken = brCfg.Metrics.Token
+	cfg.PairingPort = brCfg.Pairing.Port
+	cfg.PairingBindAddr = brCfg.Pairing.BindAddr
+	if cfg.PairingBindAddr == "" {
+		cfg.PairingBindAddr = "127.0.0.1"
+	}
+
+	cfg.LogLevel = brCfg.Logging.Level
+	cfg.LogFormat = brCfg.Logging.Format
+	cfg.LogPath = brCfg.Logging.Path
+	cfg.LogMaxSizeMB = brCfg.Logging.MaxSizeMB
+	cfg.LogMaxBackups = brCfg.Logging.MaxBackups
 
 	// Session scope defaults to per-sender
 	cfg.SessionScope = "per-sender"
 
+	// Idempotency defaults (can be overridden in bridge.json)
+	cfg.IdempotencyTTLSeconds = brCfg.IdempotencyTTLSeconds
+	if cfg.IdempotencyTTLSeconds == 0 {
+		cfg.IdempotencyTTLSeconds = 600
+	}
+	cfg.IdempotencyMaxEntries = brCfg.IdempotencyMaxEntries
+	if cfg.IdempotencyMaxEntries == 0 {
+		cfg.IdempotencyMaxEntries = 2048
+	}
+
+	// Reset trigger matcher (defaults to "/new" / "/reset" if unset)
+	cfg.ResetTriggers = brCfg.Triggers
+	cfg.ResetTriggerRegexes = brCfg.TriggerRegexes
+	cfg.ResetTriggerPrefix = brCfg.TriggerPrefixOnly
+
+	// Additional bridges, for running more than one webhook<->Gateway
+	// pairing in this process (see Config.BridgeConfigs).
+	for i, b := range brCfg.Bridges {
+		if b.WebhookURL == "" {
+			return nil, fmt.Errorf("bridges[%d].webhook_url is required in ~/.openclaw/bridge.json", i)
+		}
+
+		entry := BridgeConfig{
+			UID:        b.UID,
+			WebhookURL: b.WebhookURL,
+			WebhookTLS: b.TLS.toTLSConfig(),
+			OpenClaw: OpenClawConfig{
+				GatewayPort:  cfg.OpenClaw.GatewayPort,
+				GatewayToken: cfg.OpenClaw.GatewayToken,
+				AgentID:      "main",
+				TLS:          cfg.OpenClaw.TLS,
+			},
+			SessionScope: "per-sender",
+		}
+		if entry.UID == "" {
+			entry.UID = generateUID()
+		}
+		if b.AgentID != "" {
+			entry.OpenClaw.AgentID = b.AgentID
+		}
+		if b.GatewayPort != 0 {
+			entry.OpenClaw.GatewayPort = b.GatewayPort
+		}
+		if b.GatewayToken != "" {
+			entry.OpenClaw.GatewayToken = b.GatewayToken
+		}
+		if b.SessionScope != "" {
+			entry.SessionScope = b.SessionScope
+		}
+		if b.SessionStorePath != "" {
+			entry.SessionStorePath = filepath.Join(dir, b.SessionStorePath)
+		} else {
+			entry.SessionStorePath = filepath.Join(dir, fmt.Sprintf("sessions-%s.json", entry.UID))
+		}
+
+		entry.SessionStore = cfg.SessionStore
+		if b.SessionStore.Driver != "" {
+			entry.SessionStore = SessionStoreConfig{
+				Driver: b.SessionStore.Driver,
+				DSN:    b.SessionStore.DSN,
+			}
+		}
+
+		cfg.Bridges = append(cfg.Bridges, entry)
+	}
+
+	// Additional tenants, for routing more than one OpenClaw agent through
+	// this bridge's single webhook connection (see Config.Tenants).
+	for i, a := range brCfg.Agents {
+		if a.TenantID == "" {
+			return nil, fmt.Errorf("agents[%d].tenant_id is required in ~/.openclaw/bridge.json", i)
+		}
+
+		tenant := TenantConfig{
+			TenantID: a.TenantID,
+			OpenClaw: OpenClawConfig{
+				GatewayPort:  cfg.OpenClaw.GatewayPort,
+				GatewayToken: cfg.OpenClaw.GatewayToken,
+				AgentID:      a.TenantID,
+				TLS:          cfg.OpenClaw.TLS,
+			},
+		}
+		if a.AgentID != "" {
+			tenant.OpenClaw.AgentID = a.AgentID
+		}
+		if a.GatewayPort != 0 {
+			tenant.OpenClaw.GatewayPort = a.GatewayPort
+		}
+		if a.GatewayToken != "" {
+			tenant.OpenClaw.GatewayToken = a.GatewayToken
+		}
+		if tls := a.TLS.toTLSConfig(); tls != nil {
+			tenant.OpenClaw.TLS = tls
+		}
+
+		cfg.Tenants = append(cfg.Tenants, tenant)
+	}
+
 	return cfg, nil
 }
 
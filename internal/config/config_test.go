@@ -0,0 +1,40 @@
+package config
+
+import "testing"
+
+func TestBridgeConfigsCarriesSessionStore(t *testing.T) {
+	cfg := &Config{
+		UID:              "main",
+		WebhookURL:       "ws://example.test/a",
+		SessionStorePath: "/tmp/sessions.json",
+		SessionStore:     SessionStoreConfig{Driver: "redis", DSN: "127.0.0.1:6379"},
+		Bridges: []BridgeConfig{
+			{
+				UID:              "second",
+				WebhookURL:       "ws://example.test/b",
+				SessionStorePath: "/tmp/sessions-second.json",
+				SessionStore:     SessionStoreConfig{Driver: "bolt", DSN: "/tmp/second.db"},
+			},
+			{
+				UID:              "third",
+				WebhookURL:       "ws://example.test/c",
+				SessionStorePath: "/tmp/sessions-third.json",
+			},
+		},
+	}
+
+	bridges := cfg.BridgeConfigs()
+	if len(bridges) != 3 {
+		t.Fatalf("BridgeConfigs() returned %d entries, want 3", len(bridges))
+	}
+
+	if got := bridges[0].SessionStore; got != cfg.SessionStore {
+		t.Fatalf("first bridge SessionStore = %+v, want the top-level config %+v", got, cfg.SessionStore)
+	}
+	if got := bridges[1].SessionStore; got.Driver != "bolt" || got.DSN != "/tmp/second.db" {
+		t.Fatalf("second bridge SessionStore = %+v, want its own bolt config", got)
+	}
+	if got := bridges[2].SessionStore; got != (SessionStoreConfig{}) {
+		t.Fatalf("third bridge SessionStore = %+v, want the zero value it was configured with", got)
+	}
+}
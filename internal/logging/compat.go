@@ -0,0 +1,36 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+)
+
+// Printf, Println, and Fatalf mirror the stdlib "log" package's signatures
+// so call sites across bridge/webhook/openclaw/sessions can drop in this
+// package in place of "log" with a one-line import change, while the
+// actual output goes through whatever *slog.Logger main wired up with
+// slog.SetDefault (level filtering, JSON/text, rotation). New code in
+// those packages should prefer slog.Default()/FromContext directly so it
+// can attach structured fields instead of a formatted string - see
+// commands.CommandHandler for that style.
+func Printf(format string, args ...any) {
+	slog.Default().Info(fmt.Sprintf(format, args...))
+}
+
+// Println mirrors log.Println.
+func Println(args ...any) {
+	slog.Default().Info(fmt.Sprintln(args...))
+}
+
+// Fatalf mirrors log.Fatalf: it logs at error level and exits the process.
+func Fatalf(format string, args ...any) {
+	slog.Default().Error(fmt.Sprintf(format, args...))
+	os.Exit(1)
+}
+
+// Fatal mirrors log.Fatal: it logs at error level and exits the process.
+func Fatal(args ...any) {
+	slog.Default().Error(fmt.Sprint(args...))
+	os.Exit(1)
+}
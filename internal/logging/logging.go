@@ -0,0 +1,101 @@
+// Package logging builds the bridge's log/slog logger: a level, a text or
+// JSON handler, and an optional size/time-based rotating file writer so
+// release mode stops discarding diagnostics to /dev/null. Packages that
+// can't take a *slog.Logger as a constructor argument use the package-level
+// compat helpers (see compat.go) instead of the stdlib "log" package.
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// Options configures New. It's a plain struct (not config.Config) so this
+// package never imports internal/config - config.Config's LogLevel/
+// LogFormat/LogPath/LogMaxSizeMB/LogMaxBackups fields map onto it 1:1.
+type Options struct {
+	// Level is one of "debug", "info" (default), "warn", or "error".
+	Level string
+	// Format is "text" (default) or "json".
+	Format string
+	// Path, when set, routes output through a RotatingWriter instead of
+	// Writer (or stderr if Writer is nil).
+	Path string
+	// MaxSizeMB is RotatingWriter's rotation threshold. Defaults to 100.
+	MaxSizeMB int
+	// MaxBackups is RotatingWriter's retained-file count. Defaults to 5.
+	MaxBackups int
+	// Writer overrides the destination when Path is empty. Defaults to
+	// os.Stderr; tests pass a buffer here.
+	Writer interface {
+		Write(p []byte) (int, error)
+	}
+}
+
+// New builds a *slog.Logger from opts. The returned logger does not close
+// its writer; callers that set Path should keep the *RotatingWriter (see
+// NewRotatingWriter) to Close it on shutdown if precise flushing matters -
+// in practice the process exiting is enough.
+func New(opts Options) *slog.Logger {
+	handlerOpts := &slog.HandlerOptions{Level: parseLevel(opts.Level)}
+
+	var w interface {
+		Write(p []byte) (int, error)
+	}
+	switch {
+	case opts.Path != "":
+		w = NewRotatingWriter(opts.Path, opts.MaxSizeMB, opts.MaxBackups)
+	case opts.Writer != nil:
+		w = opts.Writer
+	default:
+		w = os.Stderr
+	}
+
+	var handler slog.Handler
+	if strings.EqualFold(opts.Format, "json") {
+		handler = slog.NewJSONHandler(w, handlerOpts)
+	} else {
+		handler = slog.NewTextHandler(w, handlerOpts)
+	}
+
+	return slog.New(handler)
+}
+
+// parseLevel maps the config string onto a slog.Level, defaulting to Info
+// for "" or anything unrecognized.
+func parseLevel(level string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// ctxKey is unexported so only this package can populate WithLogger's
+// context value.
+type ctxKey struct{}
+
+// WithLogger returns a copy of ctx carrying logger, retrievable with
+// FromContext - this is the "context-carried logger" threaded through
+// request-scoped calls (e.g. one webhook message's handling) so every log
+// line in that call chain can be annotated with request-specific fields
+// (session ID, UID) without passing a logger parameter everywhere.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, logger)
+}
+
+// FromContext returns the logger stashed by WithLogger, or slog.Default()
+// if ctx carries none.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(ctxKey{}).(*slog.Logger); ok && logger != nil {
+		return logger
+	}
+	return slog.Default()
+}
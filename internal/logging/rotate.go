@@ -0,0 +1,135 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	defaultMaxSizeMB  = 100
+	defaultMaxBackups = 5
+)
+
+// RotatingWriter is an io.Writer that rotates its underlying file once it
+// exceeds MaxSizeMB or the calendar day changes (whichever comes first),
+// renaming the old file with a timestamp suffix and pruning backups beyond
+// MaxBackups - a small, dependency-free stand-in for lumberjack.Logger.
+type RotatingWriter struct {
+	path       string
+	maxSizeMB  int
+	maxBackups int
+
+	mu        sync.Mutex
+	file      *os.File
+	size      int64
+	openedDay string
+}
+
+// NewRotatingWriter opens (creating if necessary) the log file at path. A
+// maxSizeMB/maxBackups of 0 uses the package defaults (100MB, 5 backups).
+func NewRotatingWriter(path string, maxSizeMB, maxBackups int) *RotatingWriter {
+	if maxSizeMB <= 0 {
+		maxSizeMB = defaultMaxSizeMB
+	}
+	if maxBackups <= 0 {
+		maxBackups = defaultMaxBackups
+	}
+	return &RotatingWriter{path: path, maxSizeMB: maxSizeMB, maxBackups: maxBackups}
+}
+
+// Write implements io.Writer, rotating first if needed.
+func (w *RotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.ensureOpen(); err != nil {
+		return 0, err
+	}
+
+	today := time.Now().Format("2006-01-02")
+	if today != w.openedDay || w.size+int64(len(p)) > int64(w.maxSizeMB)*1024*1024 {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Close closes the underlying file, if open.
+func (w *RotatingWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	err := w.file.Close()
+	w.file = nil
+	return err
+}
+
+// ensureOpen opens w.path for appending if it isn't already open.
+func (w *RotatingWriter) ensureOpen() error {
+	if w.file != nil {
+		return nil
+	}
+	if dir := filepath.Dir(w.path); dir != "" && dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("logging: create log directory: %w", err)
+		}
+	}
+	f, err := os.OpenFile(w.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("logging: open log file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("logging: stat log file: %w", err)
+	}
+	w.file = f
+	w.size = info.Size()
+	w.openedDay = time.Now().Format("2006-01-02")
+	return nil
+}
+
+// rotate closes the current file, renames it with a timestamp suffix, opens
+// a fresh file at w.path, and prunes backups beyond maxBackups.
+func (w *RotatingWriter) rotate() error {
+	if w.file != nil {
+		w.file.Close()
+		w.file = nil
+	}
+
+	if info, err := os.Stat(w.path); err == nil && info.Size() > 0 {
+		backupPath := fmt.Sprintf("%s.%s", w.path, time.Now().Format("20060102-150405.000"))
+		if err := os.Rename(w.path, backupPath); err != nil {
+			return fmt.Errorf("logging: rotate log file: %w", err)
+		}
+	}
+
+	if err := w.ensureOpen(); err != nil {
+		return err
+	}
+	w.pruneBackups()
+	return nil
+}
+
+// pruneBackups removes the oldest rotated files beyond maxBackups. Errors
+// are swallowed - a failed prune shouldn't block logging.
+func (w *RotatingWriter) pruneBackups() {
+	matches, err := filepath.Glob(w.path + ".*")
+	if err != nil || len(matches) <= w.maxBackups {
+		return
+	}
+	sort.Strings(matches) // timestamp suffix sorts lexicographically by age
+	for _, old := range matches[:len(matches)-w.maxBackups] {
+		os.Remove(old)
+	}
+}
@@ -0,0 +1,89 @@
+// Package metrics exposes the bridge's Prometheus registry: a small set of
+// counters/gauges/histograms instrumenting the webhook <-> OpenClaw hot
+// paths, registered against a dedicated Registry (not the global
+// prometheus.DefaultRegisterer) so embedding this package never collides
+// with a host process's own metrics.
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// Registry is the Prometheus registry served by NewServer. Callers that
+// want to add their own collectors alongside the bridge's can register
+// directly against it.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// WebhookMessagesTotal counts messages crossing the webhook boundary,
+	// labeled by direction ("inbound" from the webhook, "outbound" back to
+	// it) and the bridge instance's UID.
+	WebhookMessagesTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "bridge_webhook_messages_total",
+		Help: "Webhook messages processed, by direction and bridge UID.",
+	}, []string{"direction", "uid"})
+
+	// OpenClawEventsTotal counts events received from OpenClaw, labeled by
+	// event type (e.g. "message", "lifecycle"; "unknown" when unparseable).
+	OpenClawEventsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "bridge_openclaw_events_total",
+		Help: "OpenClaw events processed, by event type.",
+	}, []string{"type"})
+
+	// CommandInvocationsTotal counts slash-command invocations, labeled by
+	// command name and result ("ok" or "error").
+	CommandInvocationsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "bridge_command_invocations_total",
+		Help: "Slash command invocations, by command and result.",
+	}, []string{"command", "result"})
+
+	// SessionActive is the number of entries currently held by the session
+	// store, sampled after each message the bridge handles, or kept current
+	// from the store's change feed once Bridge.WatchSessionChanges is active.
+	SessionActive = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "bridge_session_active",
+		Help: "Number of sessions currently tracked by the session store.",
+	})
+
+	// MessageDuration times end-to-end handling of a webhook message or
+	// OpenClaw event, labeled by path ("webhook_message" or
+	// "openclaw_event").
+	MessageDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "bridge_message_duration_seconds",
+		Help:    "Time spent handling a webhook message or OpenClaw event end-to-end.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"path"})
+
+	// WebhookReconnectErrorsTotal counts dial/read errors surfaced by the
+	// webhook client's reconnect loop.
+	WebhookReconnectErrorsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "bridge_webhook_reconnect_errors_total",
+		Help: "Errors encountered by the webhook client's reconnect loop.",
+	})
+
+	// OpenClawReconnectErrorsTotal counts dial/read errors surfaced by the
+	// OpenClaw client's reconnect loop, mirroring WebhookReconnectErrorsTotal.
+	OpenClawReconnectErrorsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "bridge_openclaw_reconnect_errors_total",
+		Help: "Errors encountered by the OpenClaw client's reconnect loop.",
+	})
+
+	// SessionStoreLookupsTotal counts Store.GetEntry calls, labeled by
+	// result ("hit" or "miss"), so operators can alert on an unexpectedly
+	// high miss rate (e.g. a TTL set too low, or a backend losing entries).
+	SessionStoreLookupsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "bridge_session_store_lookups_total",
+		Help: "Session store GetEntry calls, by result (hit or miss).",
+	}, []string{"result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		WebhookMessagesTotal,
+		OpenClawEventsTotal,
+		CommandInvocationsTotal,
+		SessionActive,
+		MessageDuration,
+		WebhookReconnectErrorsTotal,
+		OpenClawReconnectErrorsTotal,
+		SessionStoreLookupsTotal,
+	)
+}
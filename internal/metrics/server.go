@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// StatusProvider returns a JSON-marshalable snapshot of whatever the caller
+// considers its health/throughput summary (e.g. connection state, session
+// counts). It's supplied by cmd/bridge rather than defined here, since this
+// package has no notion of bridges, sessions, or supervisors.
+type StatusProvider func() any
+
+// NewServer builds an *http.Server exposing Registry on /metrics and,
+// when status is non-nil, a JSON summary on /status, at addr (e.g.
+// ":9090"). When token is non-empty, requests to either endpoint must carry
+// "Authorization: Bearer <token>" or get a 401 - both endpoints are
+// otherwise open, so a bridge scraped over a shared host should always set
+// one.
+func NewServer(addr, token string, status StatusProvider) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", authMiddleware(token, promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})))
+	if status != nil {
+		mux.Handle("/status", authMiddleware(token, statusHandler(status)))
+	}
+	return &http.Server{Addr: addr, Handler: mux}
+}
+
+// statusHandler serves status's return value as JSON.
+func statusHandler(status StatusProvider) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(status()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// authMiddleware gates next behind a bearer token check. An empty token
+// disables the check entirely (next is served directly), matching
+// config.Config.MetricsToken being optional.
+func authMiddleware(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+	want := []byte(fmt.Sprintf("Bearer %s", token))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := []byte(r.Header.Get("Authorization"))
+		if len(got) != len(want) || subtle.ConstantTimeCompare(got, want) != 1 {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
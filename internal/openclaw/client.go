@@ -10,18 +10,71 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/backoff"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/metrics"
 )
 
 // EventCallback is called for each event from OpenClaw Gateway
 // The data is the raw JSON event message
 type EventCallback func(data []byte)
 
+// KeepaliveConfig controls the WebSocket ping/pong keepalive that detects a
+// silently-dropped connection (NAT rebind, half-open socket) long before the
+// OS would otherwise notice.
+type KeepaliveConfig struct {
+	// PingInterval is how often a ping is sent on an idle connection.
+	// Defaults to 30s.
+	PingInterval time.Duration
+	// PongWait is how long ReadMessage may block without a pong or any other
+	// frame before the connection is considered dead. Should be comfortably
+	// larger than PingInterval (defaults to 2.5x it, i.e. 75s).
+	PongWait time.Duration
+	// WriteTimeout bounds how long a ping (or any other write) may block.
+	// Defaults to 10s.
+	WriteTimeout time.Duration
+}
+
+// DefaultKeepaliveConfig returns the keepalive defaults used when NewClient
+// is passed a nil KeepaliveConfig.
+func DefaultKeepaliveConfig() KeepaliveConfig {
+	return KeepaliveConfig{
+		PingInterval: 30 * time.Second,
+		PongWait:     75 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+}
+
+// BackoffConfig controls the reconnect delay between connectionLoop
+// attempts (see internal/backoff for the decorrelated-jitter algorithm) and
+// how long a connection must stay up before that delay resets to Base,
+// instead of resetting on any successful-but-fleeting dial.
+type BackoffConfig struct {
+	Base            time.Duration
+	Cap             time.Duration
+	StableThreshold time.Duration
+}
+
+// DefaultBackoffConfig returns the backoff defaults used when NewClient is
+// passed a nil BackoffConfig.
+func DefaultBackoffConfig() BackoffConfig {
+	return BackoffConfig{
+		Base:            1 * time.Second,
+		Cap:             30 * time.Second,
+		StableThreshold: 30 * time.Second,
+	}
+}
+
 // Client is an OpenClaw Gateway WebSocket client with persistent connection
 type Client struct {
-	port    int
+	addr    string // ws:// or wss:// URL of the gateway
 	token   string
 	agentID string
 
+	keepalive       KeepaliveConfig
+	dialer          *websocket.Dialer
+	reconnectDelay  *backoff.Decorrelated
+	stableThreshold time.Duration
+
 	// Persistent connection
 	conn      *websocket.Conn
 	connMu    sync.RWMutex
@@ -30,6 +83,11 @@ type Client struct {
 	cancel    context.CancelFunc
 	wg        sync.WaitGroup
 
+	// writeMu serializes every write to conn (gorilla/websocket forbids
+	// concurrent writes): ordinary SendRaw traffic and the keepalive ping
+	// writer both go through writeLocked.
+	writeMu sync.Mutex
+
 	// Connection state notification
 	connCond *sync.Cond
 
@@ -39,6 +97,16 @@ type Client struct {
 	// Pending requests (for request/response pattern)
 	pendingRequests   map[string]chan []byte
 	pendingRequestsMu sync.RWMutex
+
+	// outbox is the persistent send queue installed by EnableOutbox, or nil
+	// if outbox support isn't in use.
+	outbox *outbox
+
+	// methods is the RegisterMethod registry for server-initiated "req"
+	// frames; methodWorkers bounds how many are handled concurrently.
+	methods       map[string]MethodHandler
+	methodsMu     sync.RWMutex
+	methodWorkers chan struct{}
 }
 
 // requestPool is a sync.Pool for reusing request objects
@@ -67,15 +135,38 @@ type agentRequestParams struct {
 	IdempotencyKey string `json:"idempotencyKey"`
 }
 
-// NewClient creates a new OpenClaw Gateway client
-func NewClient(port int, token, agentID string) *Client {
+// NewClient creates a new OpenClaw Gateway client. addr is the gateway's
+// WebSocket URL (ws:// or, with tlsConfig set, wss://). keepalive,
+// tlsConfig and backoffConfig are all optional - pass nil for any of them
+// to use their respective defaults.
+func NewClient(addr string, token, agentID string, tlsConfig *TLSConfig, keepalive *KeepaliveConfig, backoffConfig *BackoffConfig) (*Client, error) {
+	cfg := DefaultKeepaliveConfig()
+	if keepalive != nil {
+		cfg = *keepalive
+	}
+
+	bcfg := DefaultBackoffConfig()
+	if backoffConfig != nil {
+		bcfg = *backoffConfig
+	}
+
+	dialer, err := buildDialer(tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	return &Client{
-		port:            port,
+		addr:            addr,
 		token:           token,
 		agentID:         agentID,
+		keepalive:       cfg,
+		dialer:          dialer,
+		reconnectDelay:  backoff.NewDecorrelated(backoff.Config{Base: bcfg.Base, Cap: bcfg.Cap}),
+		stableThreshold: bcfg.StableThreshold,
 		pendingRequests: make(map[string]chan []byte),
 		connCond:        sync.NewCond(&sync.Mutex{}),
-	}
+		methodWorkers:   make(chan struct{}, defaultMethodWorkers),
+	}, nil
 }
 
 // SetEventCallback sets the callback for OpenClaw events
@@ -88,6 +179,37 @@ func (c *Client) AgentID() string {
 	return c.agentID
 }
 
+// IsConnected reports whether the client currently has a live Gateway
+// connection.
+func (c *Client) IsConnected() bool {
+	return c.connected.Load()
+}
+
+// EnableOutbox turns on the persistent send queue: once enabled, SendRaw
+// (and anything built on it, like SendAgentRequest) no longer fails when the
+// gateway is unreachable - it appends the frame to the WAL at path instead,
+// and connectAndRead replays queued frames in order on the next successful
+// reconnect. path is created (along with any missing parent directories) if
+// it doesn't exist yet; maxBytes bounds the WAL size before it's compacted
+// down to just its unacked entries. Must be called before Connect.
+func (c *Client) EnableOutbox(path string, maxBytes int64) error {
+	ob, err := openOutbox(path, maxBytes)
+	if err != nil {
+		return err
+	}
+	c.outbox = ob
+	return nil
+}
+
+// OutboxLen reports how many outbox entries are still awaiting a response,
+// or 0 if the outbox isn't enabled.
+func (c *Client) OutboxLen() int {
+	if c.outbox == nil {
+		return 0
+	}
+	return c.outbox.len()
+}
+
 // Connect establishes a persistent WebSocket connection to the gateway
 func (c *Client) Connect(ctx context.Context) error {
 	c.ctx, c.cancel = context.WithCancel(ctx)
@@ -154,13 +276,14 @@ func (c *Client) Close() error {
 	return nil
 }
 
-// connectionLoop maintains a persistent connection with auto-reconnect
+// connectionLoop maintains a persistent connection with auto-reconnect. The
+// delay between attempts follows decorrelated jitter (see internal/backoff)
+// rather than plain doubling, so many bridges reconnecting at once don't
+// all retry in lockstep; connectAndRead's deferred cleanup is what resets
+// it, and only once the connection has proven stable.
 func (c *Client) connectionLoop() {
 	defer c.wg.Done()
 
-	reconnectDelay := 1 * time.Second
-	maxReconnectDelay := 30 * time.Second
-
 	for {
 		select {
 		case <-c.ctx.Done():
@@ -171,21 +294,16 @@ func (c *Client) connectionLoop() {
 
 		if err := c.connectAndRead(); err != nil {
 			log.Printf("[OpenClaw] Connection error: %v", err)
-
-			// Exponential backoff for reconnection
-			if reconnectDelay < maxReconnectDelay {
-				reconnectDelay *= 2
-			}
-		} else {
-			// Successful connection, reset delay
-			reconnectDelay = 1 * time.Second
+			metrics.OpenClawReconnectErrorsTotal.Inc()
 		}
 
+		delay := c.reconnectDelay.Next()
+
 		// Wait before reconnecting (or exit if context cancelled)
 		select {
 		case <-c.ctx.Done():
 			return
-		case <-time.After(reconnectDelay):
+		case <-time.After(delay):
 			log.Printf("[OpenClaw] Reconnecting...")
 		}
 	}
@@ -193,10 +311,14 @@ func (c *Client) connectionLoop() {
 
 // connectAndRead establishes connection and reads messages
 func (c *Client) connectAndRead() error {
-	url := fmt.Sprintf("ws://127.0.0.1:%d", c.port)
-
-	log.Printf("[OpenClaw] Connecting to %s", url)
-	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	// connCtx is cancelled the instant this connection goes away, so any
+	// RegisterMethod handler still running against it is cancelled too
+	// rather than outliving the connection that expects its response.
+	connCtx, connCancel := context.WithCancel(c.ctx)
+	defer connCancel()
+
+	log.Printf("[OpenClaw] Connecting to %s", c.addr)
+	conn, _, err := c.dialer.Dial(c.addr, nil)
 	if err != nil {
 		return fmt.Errorf("failed to dial: %w", err)
 	}
@@ -211,11 +333,36 @@ func (c *Client) connectAndRead() error {
 		return fmt.Errorf("failed to send connect request: %w", err)
 	}
 
+	c.installKeepaliveHandlers(conn)
+	conn.SetReadDeadline(time.Now().Add(c.keepalive.PongWait))
+
+	pingDone := make(chan struct{})
+	go c.pingLoop(conn, pingDone)
+	defer close(pingDone)
+
+	if c.outbox != nil {
+		if err := c.outbox.drain(func(data []byte) error {
+			return c.writeMessage(conn, websocket.TextMessage, data)
+		}); err != nil {
+			conn.Close()
+			return fmt.Errorf("failed to drain outbox: %w", err)
+		}
+	}
+
+	connectedAt := time.Now()
 	c.connected.Store(true)
 	c.connCond.Broadcast() // Wake up any waiters
 	defer func() {
 		c.connected.Store(false)
 		c.connCond.Broadcast() // Wake up any waiters on disconnect
+
+		// Only treat the connection as proven stable - and so reset the
+		// backoff - if it survived at least stableThreshold; a connection
+		// that dies immediately after handshake shouldn't reset a backoff
+		// that's trying to ride out a flapping gateway.
+		if time.Since(connectedAt) >= c.stableThreshold {
+			c.reconnectDelay.Reset()
+		}
 	}()
 
 	// Read messages and forward to callback
@@ -230,6 +377,9 @@ func (c *Client) connectAndRead() error {
 		// Check if this is a response to a pending request
 		c.handlePossibleResponse(message)
 
+		// Check if this is a server-initiated request for a registered method
+		c.handlePossibleRequest(connCtx, conn, message)
+
 		// Forward raw event to callback
 		if c.onEvent != nil {
 			c.onEvent(message)
@@ -237,6 +387,54 @@ func (c *Client) connectAndRead() error {
 	}
 }
 
+// installKeepaliveHandlers wires up the pong handler (extends the read
+// deadline whenever the peer acks one of our pings) and the ping handler
+// (replies to a peer-initiated ping and also extends the read deadline).
+func (c *Client) installKeepaliveHandlers(conn *websocket.Conn) {
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(c.keepalive.PongWait))
+	})
+	conn.SetPingHandler(func(data string) error {
+		conn.SetReadDeadline(time.Now().Add(c.keepalive.PongWait))
+		err := c.writeControl(conn, websocket.PongMessage, []byte(data))
+		if err == websocket.ErrCloseSent {
+			return nil
+		}
+		return err
+	})
+}
+
+// pingLoop sends a PingMessage every PingInterval until done is closed. A
+// failed ping means the connection is already dead; conn.Close nudges
+// ReadMessage in connectAndRead to return promptly instead of waiting out
+// the read deadline.
+func (c *Client) pingLoop(conn *websocket.Conn, done <-chan struct{}) {
+	ticker := time.NewTicker(c.keepalive.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.writeControl(conn, websocket.PingMessage, nil); err != nil {
+				conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// writeControl sends a control frame, serialized against every other write
+// on conn via writeMu (gorilla/websocket forbids concurrent writes).
+func (c *Client) writeControl(conn *websocket.Conn, messageType int, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return conn.WriteControl(messageType, data, time.Now().Add(c.keepalive.WriteTimeout))
+}
+
 // handlePossibleResponse checks if message is a response to a pending request
 func (c *Client) handlePossibleResponse(message []byte) {
 	var responseWrapper struct {
@@ -253,6 +451,10 @@ func (c *Client) handlePossibleResponse(message []byte) {
 		return
 	}
 
+	if c.outbox != nil {
+		c.outbox.ack(responseWrapper.ID)
+	}
+
 	c.pendingRequestsMu.RLock()
 	ch, exists := c.pendingRequests[responseWrapper.ID]
 	c.pendingRequestsMu.RUnlock()
@@ -290,7 +492,20 @@ func (c *Client) sendConnectRequest(conn *websocket.Conn) error {
 		},
 	}
 
-	return conn.WriteJSON(connectReq)
+	data, err := json.Marshal(connectReq)
+	if err != nil {
+		return err
+	}
+	return c.writeMessage(conn, websocket.TextMessage, data)
+}
+
+// writeMessage sends a data frame, serialized against every other write on
+// conn via writeMu (gorilla/websocket forbids concurrent writes).
+func (c *Client) writeMessage(conn *websocket.Conn, messageType int, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	conn.SetWriteDeadline(time.Now().Add(c.keepalive.WriteTimeout))
+	return conn.WriteMessage(messageType, data)
 }
 
 // SendRaw sends raw JSON data to OpenClaw Gateway
@@ -307,7 +522,7 @@ func (c *Client) SendRaw(data []byte) error {
 		case <-c.ctx.Done():
 			return fmt.Errorf("client closed")
 		case <-timeout.C:
-			return fmt.Errorf("timeout waiting for connection")
+			return c.enqueueOrFail(data, fmt.Errorf("timeout waiting for connection"))
 		default:
 			// Wait for signal with timeout
 			done := make(chan struct{})
@@ -319,7 +534,7 @@ func (c *Client) SendRaw(data []byte) error {
 			case <-done:
 				// Woke up from Wait, check connected again
 			case <-timeout.C:
-				return fmt.Errorf("timeout waiting for connection")
+				return c.enqueueOrFail(data, fmt.Errorf("timeout waiting for connection"))
 			case <-c.ctx.Done():
 				return fmt.Errorf("client closed")
 			}
@@ -331,18 +546,32 @@ func (c *Client) SendRaw(data []byte) error {
 	c.connMu.RUnlock()
 
 	if conn == nil {
-		return fmt.Errorf("connection lost")
+		return c.enqueueOrFail(data, fmt.Errorf("connection lost"))
 	}
 
 	// Don't log message content for privacy
 
-	if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
-		return fmt.Errorf("failed to send: %w", err)
+	if err := c.writeMessage(conn, websocket.TextMessage, data); err != nil {
+		return c.enqueueOrFail(data, fmt.Errorf("failed to send: %w", err))
 	}
 
 	return nil
 }
 
+// enqueueOrFail is SendRaw's failure path: with the outbox enabled it
+// appends data for later replay (see connectAndRead's drain) and swallows
+// sendErr - the caller's request is durably queued, not lost. Without an
+// outbox it just returns sendErr, preserving SendRaw's old behavior.
+func (c *Client) enqueueOrFail(data []byte, sendErr error) error {
+	if c.outbox == nil {
+		return sendErr
+	}
+	if err := c.outbox.append(data); err != nil {
+		return fmt.Errorf("%w (outbox append also failed: %v)", sendErr, err)
+	}
+	return nil
+}
+
 // SendAgentRequest sends an agent request to OpenClaw using object pooling
 func (c *Client) SendAgentRequest(message, sessionKey string) error {
 	// Get request from pool
@@ -0,0 +1,110 @@
+package openclaw
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/gorilla/websocket"
+)
+
+// MethodHandler answers a server-initiated "req" frame registered via
+// RegisterMethod. It runs on the client's bounded worker pool; ctx is
+// cancelled if the connection that carried the request drops before the
+// handler returns.
+type MethodHandler func(ctx context.Context, id string, params json.RawMessage) (result interface{}, err error)
+
+// methodError is the {code,message} shape inside an {type:"error"} response.
+type methodError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// defaultMethodWorkers bounds how many server-initiated requests a Client
+// answers concurrently.
+const defaultMethodWorkers = 8
+
+// RegisterMethod installs handler for method: every incoming
+// {type:"req", method:method} frame from the gateway is dispatched to it on
+// the worker pool, and its result (or error) is written back as a
+// {type:"response"} or {type:"error"} frame. Registering the same method
+// twice replaces the earlier handler. Safe to call at any time, including
+// after Connect.
+func (c *Client) RegisterMethod(method string, handler MethodHandler) {
+	c.methodsMu.Lock()
+	defer c.methodsMu.Unlock()
+	if c.methods == nil {
+		c.methods = make(map[string]MethodHandler)
+	}
+	c.methods[method] = handler
+}
+
+// handlePossibleRequest checks whether message is a server-initiated "req"
+// frame for a registered method and, if so, dispatches it on the worker
+// pool. connCtx is cancelled by connectAndRead when the connection that
+// delivered message drops, which in turn cancels any handler still running
+// for it.
+func (c *Client) handlePossibleRequest(connCtx context.Context, conn *websocket.Conn, message []byte) {
+	var reqWrapper struct {
+		Type   string          `json:"type"`
+		ID     string          `json:"id"`
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+	}
+	if err := json.Unmarshal(message, &reqWrapper); err != nil {
+		return
+	}
+	if reqWrapper.Type != "req" || reqWrapper.Method == "" {
+		return
+	}
+
+	c.methodsMu.RLock()
+	handler, ok := c.methods[reqWrapper.Method]
+	c.methodsMu.RUnlock()
+	if !ok {
+		return
+	}
+
+	select {
+	case c.methodWorkers <- struct{}{}:
+	case <-connCtx.Done():
+		return
+	}
+
+	go func() {
+		defer func() { <-c.methodWorkers }()
+		c.runMethod(connCtx, conn, handler, reqWrapper.ID, reqWrapper.Method, reqWrapper.Params)
+	}()
+}
+
+// runMethod invokes handler and writes its result (or error) back as a
+// response/error frame.
+func (c *Client) runMethod(ctx context.Context, conn *websocket.Conn, handler MethodHandler, id, method string, params json.RawMessage) {
+	result, err := handler(ctx, id, params)
+
+	var frame map[string]interface{}
+	if err != nil {
+		log.Printf("[OpenClaw] Method %q (id=%s) failed: %v", method, id, err)
+		frame = map[string]interface{}{
+			"type":  "error",
+			"id":    id,
+			"error": methodError{Code: "handler_error", Message: err.Error()},
+		}
+	} else {
+		frame = map[string]interface{}{
+			"type": "response",
+			"id":   id,
+			"data": result,
+		}
+	}
+
+	data, err := json.Marshal(frame)
+	if err != nil {
+		log.Printf("[OpenClaw] Failed to encode response for method %q (id=%s): %v", method, id, err)
+		return
+	}
+
+	if err := c.writeMessage(conn, websocket.TextMessage, data); err != nil {
+		log.Printf("[OpenClaw] Failed to send response for method %q (id=%s): %v", method, id, err)
+	}
+}
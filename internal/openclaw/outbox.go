@@ -0,0 +1,287 @@
+package openclaw
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// outboxRecord is the on-disk representation of one queued frame. ID and
+// IdempotencyKey are pulled out of Data at enqueue time purely so ack and
+// OutboxLen don't need to re-parse JSON on every call.
+type outboxRecord struct {
+	ID             string `json:"id,omitempty"`
+	IdempotencyKey string `json:"idempotencyKey,omitempty"`
+	Data           []byte `json:"data"`
+}
+
+// outboxEntry is an outboxRecord held in memory alongside its acked state.
+// Acked entries stay in entries (so Len/compact can still see them) until
+// the next compact() drops them from both the slice and the file.
+type outboxEntry struct {
+	outboxRecord
+	acked bool
+}
+
+// outbox is a bounded, persistent WAL backing Client.EnableOutbox: frames
+// that couldn't be sent while disconnected are appended as length-prefixed,
+// fsynced records, replayed in order on reconnect (see drain, called from
+// Client.connectAndRead),
+// and dropped once their response arrives (see ack). When the file grows
+// past maxBytes it is compacted by rewriting only the still-unacked
+// entries.
+type outbox struct {
+	mu       sync.Mutex
+	path     string
+	maxBytes int64
+	file     *os.File
+	size     int64
+	entries  []*outboxEntry
+}
+
+// openOutbox opens (creating if necessary) the WAL at path and loads any
+// entries left over from a previous run. Every loaded entry starts out
+// unacked: acked state lives only in memory, so an entry acked before the
+// previous run ended but not yet compacted out is replayed again.
+func openOutbox(path string, maxBytes int64) (*outbox, error) {
+	if dir := filepath.Dir(path); dir != "" && dir != "." {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, fmt.Errorf("outbox: create directory: %w", err)
+		}
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("outbox: open %s: %w", path, err)
+	}
+
+	entries, size, err := readOutboxEntries(file)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if _, err := file.Seek(0, io.SeekEnd); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("outbox: seek %s: %w", path, err)
+	}
+
+	ob := &outbox{path: path, maxBytes: maxBytes, file: file, size: size, entries: entries}
+	if maxBytes > 0 && size > maxBytes {
+		if err := ob.compactLocked(); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	return ob, nil
+}
+
+// readOutboxEntries reads every length-prefixed record from the start of
+// file, leaving the file offset wherever it happens to land (callers seek
+// to the end afterward).
+func readOutboxEntries(file *os.File) ([]*outboxEntry, int64, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, 0, fmt.Errorf("outbox: seek %s: %w", file.Name(), err)
+	}
+
+	var entries []*outboxEntry
+	var size int64
+	var lenBuf [4]byte
+	for {
+		if _, err := io.ReadFull(file, lenBuf[:]); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, 0, fmt.Errorf("outbox: read %s: %w", file.Name(), err)
+		}
+		recLen := binary.BigEndian.Uint32(lenBuf[:])
+		payload := make([]byte, recLen)
+		if _, err := io.ReadFull(file, payload); err != nil {
+			// A truncated trailing record means a write was interrupted
+			// mid-append; treat everything before it as the durable log
+			// and stop, rather than failing the whole load.
+			break
+		}
+
+		var rec outboxRecord
+		if err := json.Unmarshal(payload, &rec); err != nil {
+			break
+		}
+		entries = append(entries, &outboxEntry{outboxRecord: rec})
+		size += int64(4 + recLen)
+	}
+	return entries, size, nil
+}
+
+// append enqueues data (a marshalled frame) for later delivery, extracting
+// its request id and idempotency key (if any) for ack/compaction bookkeeping.
+func (o *outbox) append(data []byte) error {
+	id, idempotencyKey := parseFrameMeta(data)
+	rec := outboxRecord{ID: id, IdempotencyKey: idempotencyKey, Data: data}
+
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("outbox: encode record: %w", err)
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	var lenBuf [4]byte
+	binary.BigEndian.PutUint32(lenBuf[:], uint32(len(payload)))
+	if _, err := o.file.Write(lenBuf[:]); err != nil {
+		return fmt.Errorf("outbox: write %s: %w", o.path, err)
+	}
+	if _, err := o.file.Write(payload); err != nil {
+		return fmt.Errorf("outbox: write %s: %w", o.path, err)
+	}
+	if err := o.file.Sync(); err != nil {
+		return fmt.Errorf("outbox: fsync %s: %w", o.path, err)
+	}
+
+	o.size += int64(4 + len(payload))
+	o.entries = append(o.entries, &outboxEntry{outboxRecord: rec})
+
+	if o.maxBytes > 0 && o.size > o.maxBytes {
+		return o.compactLocked()
+	}
+	return nil
+}
+
+// ack marks the entry for id (the frame's "id" field, as seen again in the
+// matching response by Client.handlePossibleResponse) as delivered, so the
+// next compaction drops it from the WAL.
+func (o *outbox) ack(id string) {
+	if id == "" {
+		return
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for _, e := range o.entries {
+		if e.ID == id {
+			e.acked = true
+			return
+		}
+	}
+}
+
+// drain delivers every unacked entry in order via send, stopping at the
+// first failure (the connection that failure implies is already dead, so
+// the remaining entries stay queued for the next reconnect attempt).
+func (o *outbox) drain(send func([]byte) error) error {
+	o.mu.Lock()
+	pending := make([]*outboxEntry, 0, len(o.entries))
+	for _, e := range o.entries {
+		if !e.acked {
+			pending = append(pending, e)
+		}
+	}
+	o.mu.Unlock()
+
+	for _, e := range pending {
+		if err := send(e.Data); err != nil {
+			return fmt.Errorf("outbox: replay %s: %w", e.ID, err)
+		}
+	}
+	return nil
+}
+
+// len reports how many entries are still awaiting a response.
+func (o *outbox) len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	n := 0
+	for _, e := range o.entries {
+		if !e.acked {
+			n++
+		}
+	}
+	return n
+}
+
+// compactLocked rewrites the WAL with only the unacked entries. Callers
+// must hold o.mu.
+func (o *outbox) compactLocked() error {
+	kept := o.entries[:0]
+	var size int64
+	tmpPath := o.path + ".compact"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0600)
+	if err != nil {
+		return fmt.Errorf("outbox: create %s: %w", tmpPath, err)
+	}
+
+	var lenBuf [4]byte
+	for _, e := range o.entries {
+		if e.acked {
+			continue
+		}
+		payload, err := json.Marshal(e.outboxRecord)
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmpPath)
+			return fmt.Errorf("outbox: encode record during compact: %w", err)
+		}
+		binary.BigEndian.PutUint32(lenBuf[:], uint32(len(payload)))
+		if _, err := tmp.Write(lenBuf[:]); err != nil {
+			tmp.Close()
+			os.Remove(tmpPath)
+			return fmt.Errorf("outbox: write %s: %w", tmpPath, err)
+		}
+		if _, err := tmp.Write(payload); err != nil {
+			tmp.Close()
+			os.Remove(tmpPath)
+			return fmt.Errorf("outbox: write %s: %w", tmpPath, err)
+		}
+		size += int64(4 + len(payload))
+		kept = append(kept, e)
+	}
+
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("outbox: fsync %s: %w", tmpPath, err)
+	}
+	tmp.Close()
+
+	o.file.Close()
+	if err := os.Rename(tmpPath, o.path); err != nil {
+		return fmt.Errorf("outbox: replace %s: %w", o.path, err)
+	}
+
+	file, err := os.OpenFile(o.path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return fmt.Errorf("outbox: reopen %s: %w", o.path, err)
+	}
+	if _, err := file.Seek(0, io.SeekEnd); err != nil {
+		file.Close()
+		return fmt.Errorf("outbox: seek %s: %w", o.path, err)
+	}
+
+	o.file = file
+	o.entries = kept
+	o.size = size
+	return nil
+}
+
+// parseFrameMeta best-effort extracts the "id" and "params.idempotencyKey"
+// fields shared by every frame this client sends (see agentRequest and
+// sendRequestAndWait). Frames that don't carry them (e.g. they fail to
+// parse) are still queued - id and idempotencyKey just come back empty, so
+// ack by id becomes a no-op for that entry.
+func parseFrameMeta(data []byte) (id, idempotencyKey string) {
+	var meta struct {
+		ID     string `json:"id"`
+		Params struct {
+			IdempotencyKey string `json:"idempotencyKey"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(data, &meta); err == nil {
+		id = meta.ID
+		idempotencyKey = meta.Params.IdempotencyKey
+	}
+	return id, idempotencyKey
+}
@@ -0,0 +1,56 @@
+package openclaw
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// SkillInfo describes a skill the Gateway's agent can run, as returned by
+// ListSkills.
+type SkillInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// CommandInfo describes a slash command the Gateway itself exposes (as
+// opposed to the bridge-local ones in internal/commands), as returned by
+// ListCommands.
+type CommandInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// ListSkills asks the Gateway for its current skill list.
+func (c *Client) ListSkills() ([]SkillInfo, error) {
+	data, err := c.sendRequestAndWait("skills.list", nil, 5*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list skills: %w", err)
+	}
+
+	var resp struct {
+		Skills []SkillInfo `json:"skills"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse skills response: %w", err)
+	}
+
+	return resp.Skills, nil
+}
+
+// ListCommands asks the Gateway for its current command list.
+func (c *Client) ListCommands() ([]CommandInfo, error) {
+	data, err := c.sendRequestAndWait("commands.list", nil, 5*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commands: %w", err)
+	}
+
+	var resp struct {
+		Commands []CommandInfo `json:"commands"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse commands response: %w", err)
+	}
+
+	return resp.Commands, nil
+}
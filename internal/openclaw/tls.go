@@ -0,0 +1,74 @@
+package openclaw
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/gorilla/websocket"
+)
+
+// TLSConfig configures wss:// and optional mTLS for the gateway connection.
+// A nil *TLSConfig (the NewClient default) leaves the dialer untouched, so
+// ws:// URLs work exactly as before.
+type TLSConfig struct {
+	// CAFile is a PEM CA bundle used to verify the server certificate,
+	// instead of the system trust store.
+	CAFile string
+	// CertFile and KeyFile are a PEM client certificate/key pair presented
+	// for mTLS. Both must be set together, or neither.
+	CertFile string
+	KeyFile  string
+	// ServerName overrides the SNI/cert-verification hostname, for
+	// connecting by IP or through a tunnel.
+	ServerName string
+	// Insecure disables server certificate verification entirely. Logs a
+	// warning at dialer construction time.
+	Insecure bool
+}
+
+// buildDialer returns websocket.DefaultDialer when tlsConfig is nil, or a
+// dialer carrying a *tls.Config built from it otherwise.
+func buildDialer(tlsConfig *TLSConfig) (*websocket.Dialer, error) {
+	if tlsConfig == nil {
+		return websocket.DefaultDialer, nil
+	}
+
+	cfg := &tls.Config{
+		ServerName:         tlsConfig.ServerName,
+		InsecureSkipVerify: tlsConfig.Insecure,
+	}
+
+	if tlsConfig.Insecure {
+		log.Printf("[OpenClaw] WARNING: TLS certificate verification is disabled (InsecureSkipVerify) - do not use this in production")
+	}
+
+	if tlsConfig.CAFile != "" {
+		pem, err := os.ReadFile(tlsConfig.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls: read CA bundle %s: %w", tlsConfig.CAFile, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("tls: no certificates found in CA bundle %s", tlsConfig.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if tlsConfig.CertFile != "" || tlsConfig.KeyFile != "" {
+		if tlsConfig.CertFile == "" || tlsConfig.KeyFile == "" {
+			return nil, fmt.Errorf("tls: cert_file and key_file must both be set for mTLS")
+		}
+		cert, err := tls.LoadX509KeyPair(tlsConfig.CertFile, tlsConfig.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("tls: load client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	dialer := *websocket.DefaultDialer
+	dialer.TLSClientConfig = cfg
+	return &dialer, nil
+}
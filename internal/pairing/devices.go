@@ -0,0 +1,121 @@
+package pairing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// devicesFileName is where DeviceStore persists paired devices under
+// config.Dir().
+const devicesFileName = "pairing-devices.json"
+
+// Device is one mapp client that has completed enrollment. CredentialHash
+// is the only record of its long-term credential - the plaintext is
+// returned to the device once, by RedeemToken, and never stored.
+type Device struct {
+	ID             string     `json:"id"`
+	PubKeyB64      string     `json:"pubKey"`
+	UID            string     `json:"uid"`
+	WSURL          string     `json:"wsUrl"`
+	CredentialHash string     `json:"credentialHash"`
+	PairedAt       time.Time  `json:"pairedAt"`
+	RevokedAt      *time.Time `json:"revokedAt,omitempty"`
+}
+
+// DeviceStore is a small file-backed registry of paired devices, following
+// the same load/mutate/atomic-rename pattern as sessions.FileBackend.
+type DeviceStore struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewDeviceStore opens (creating if necessary) the device registry at path.
+func NewDeviceStore(path string) (*DeviceStore, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		if err := os.WriteFile(path, []byte("[]"), 0600); err != nil {
+			return nil, fmt.Errorf("pairing: create device store: %w", err)
+		}
+	}
+	return &DeviceStore{path: path}, nil
+}
+
+// List returns every device this bridge has paired, including revoked ones
+// (so "pair list" can show revocation status rather than hiding history).
+func (s *DeviceStore) List() ([]*Device, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.loadLocked()
+}
+
+// Add registers a newly-redeemed device.
+func (s *DeviceStore) Add(device *Device) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	devices, err := s.loadLocked()
+	if err != nil {
+		return err
+	}
+	devices = append(devices, device)
+	return s.saveLocked(devices)
+}
+
+// Revoke marks deviceID's credential as no longer valid. It returns an
+// error if no such device exists.
+func (s *DeviceStore) Revoke(deviceID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	devices, err := s.loadLocked()
+	if err != nil {
+		return err
+	}
+	for _, d := range devices {
+		if d.ID == deviceID {
+			if d.RevokedAt == nil {
+				now := time.Now()
+				d.RevokedAt = &now
+			}
+			return s.saveLocked(devices)
+		}
+	}
+	return fmt.Errorf("pairing: no such device %q", deviceID)
+}
+
+func (s *DeviceStore) loadLocked() ([]*Device, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("pairing: read device store: %w", err)
+	}
+	var devices []*Device
+	if err := json.Unmarshal(data, &devices); err != nil {
+		return nil, fmt.Errorf("pairing: parse device store: %w", err)
+	}
+	return devices, nil
+}
+
+func (s *DeviceStore) saveLocked(devices []*Device) error {
+	data, err := json.MarshalIndent(devices, "", "  ")
+	if err != nil {
+		return fmt.Errorf("pairing: marshal device store: %w", err)
+	}
+
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("pairing: write temp file: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("pairing: rename device store: %w", err)
+	}
+	return nil
+}
+
+func newDeviceID() string {
+	return uuid.NewString()
+}
@@ -0,0 +1,50 @@
+package pairing
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestDeviceStoreAddListRevoke(t *testing.T) {
+	store, err := NewDeviceStore(filepath.Join(t.TempDir(), "pairing-devices.json"))
+	if err != nil {
+		t.Fatalf("NewDeviceStore() error = %v", err)
+	}
+
+	device := &Device{ID: "dev-1", UID: "uid-1", PairedAt: time.Now()}
+	if err := store.Add(device); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	devices, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(devices) != 1 || devices[0].RevokedAt != nil {
+		t.Fatalf("List() = %+v, want one active device", devices)
+	}
+
+	if err := store.Revoke("dev-1"); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+
+	devices, err = store.List()
+	if err != nil {
+		t.Fatalf("List() after revoke error = %v", err)
+	}
+	if len(devices) != 1 || devices[0].RevokedAt == nil {
+		t.Fatalf("List() after revoke = %+v, want the device marked revoked", devices)
+	}
+}
+
+func TestDeviceStoreRevokeUnknownDevice(t *testing.T) {
+	store, err := NewDeviceStore(filepath.Join(t.TempDir(), "pairing-devices.json"))
+	if err != nil {
+		t.Fatalf("NewDeviceStore() error = %v", err)
+	}
+
+	if err := store.Revoke("does-not-exist"); err == nil {
+		t.Fatal("Revoke() of an unknown device = nil error, want an error")
+	}
+}
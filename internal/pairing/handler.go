@@ -0,0 +1,110 @@
+package pairing
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+)
+
+// redeemRequest is the POST /pair/redeem body: the token from the QR code,
+// plus the device's own public key to bind the issued credential to.
+type redeemRequest struct {
+	Token        string `json:"token"`
+	DevicePubKey string `json:"devicePubKey"`
+}
+
+// redeemResponse is returned exactly once; the credential is not
+// recoverable afterwards (see Device.CredentialHash).
+type redeemResponse struct {
+	DeviceID   string `json:"deviceId"`
+	Credential string `json:"credential"`
+	UID        string `json:"uid"`
+	WSURL      string `json:"wsUrl"`
+}
+
+// mintRequest is the POST /pair/mint body: the uid/wsUrl of the bridge to
+// mint a fresh enrollment token for (see Config.BridgeConfigs - a
+// multi-bridge daemon has one pairing QR per uid).
+type mintRequest struct {
+	UID   string `json:"uid"`
+	WSURL string `json:"wsUrl"`
+}
+
+// mintResponse carries the freshly minted token, for the caller to embed in
+// a QR code (see "openclaw-bridge pair qr").
+type mintResponse struct {
+	Token string `json:"token"`
+}
+
+// RedeemHandler returns an http.HandlerFunc implementing POST /pair/redeem:
+// it exchanges a short-lived enrollment token for a long-term device
+// credential. Mount it on whatever mux the caller's admin HTTP server
+// already runs (see cmd/bridge).
+func (m *Manager) RedeemHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req redeemRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		devicePubKey, err := base64.StdEncoding.DecodeString(req.DevicePubKey)
+		if err != nil {
+			http.Error(w, "invalid devicePubKey", http.StatusBadRequest)
+			return
+		}
+
+		device, credential, err := m.RedeemToken(req.Token, devicePubKey)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(redeemResponse{
+			DeviceID:   device.ID,
+			Credential: credential,
+			UID:        device.UID,
+			WSURL:      device.WSURL,
+		})
+	}
+}
+
+// MintHandler returns an http.HandlerFunc implementing POST /pair/mint: it
+// mints a fresh enrollment token for the running daemon's live Manager, so
+// "openclaw-bridge pair qr" can print a new QR without restarting the
+// daemon - and, since MintToken supersedes any token already pending for
+// that uid, without leaving the previous QR's token redeemable either. Mount
+// it on the same mux as RedeemHandler (see cmd/bridge's startPairingServer).
+func (m *Manager) MintHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req mintRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+		if req.UID == "" || req.WSURL == "" {
+			http.Error(w, "uid and wsUrl are required", http.StatusBadRequest)
+			return
+		}
+
+		token, err := m.MintToken(req.WSURL, req.UID, DefaultTokenTTL)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(mintResponse{Token: token})
+	}
+}
@@ -0,0 +1,81 @@
+// Package pairing implements the bridge's device-enrollment flow: a
+// short-lived, signed-and-encrypted token is minted into the connection QR
+// code instead of the raw webhook URL and UID, and a paired mapp client
+// redeems it exactly once for a long-term device credential. See Manager.
+package pairing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// keyFileName is where LoadOrCreateKeyPair persists the bridge's signing
+// key under config.Dir().
+const keyFileName = "pairing-key.json"
+
+// keyFileJSON is the on-disk representation of a KeyPair: just the Ed25519
+// seed, from which both the private and public key are deterministically
+// derivable.
+type keyFileJSON struct {
+	SeedB64 string `json:"seed"`
+}
+
+// KeyPair is the bridge's long-lived Ed25519 signing identity, used to sign
+// enrollment tokens (so a redeeming device can trust they came from this
+// bridge) and, via HKDF, to derive the symmetric key that encrypts them.
+type KeyPair struct {
+	Private ed25519.PrivateKey
+	Public  ed25519.PublicKey
+}
+
+// LoadOrCreateKeyPair loads the signing key persisted under dir, generating
+// and persisting a new one on first run. The seed is only ever read from and
+// written to this file; the public half is used by RedeemToken to verify
+// token signatures and is not sent to devices.
+func LoadOrCreateKeyPair(dir string) (*KeyPair, error) {
+	path := filepath.Join(dir, keyFileName)
+
+	data, err := os.ReadFile(path)
+	if err == nil {
+		var kf keyFileJSON
+		if err := json.Unmarshal(data, &kf); err != nil {
+			return nil, fmt.Errorf("pairing: parse key file: %w", err)
+		}
+		seed, err := base64.StdEncoding.DecodeString(kf.SeedB64)
+		if err != nil {
+			return nil, fmt.Errorf("pairing: decode key file: %w", err)
+		}
+		return keyPairFromSeed(seed), nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("pairing: read key file: %w", err)
+	}
+
+	seed := make([]byte, ed25519.SeedSize)
+	if _, err := rand.Read(seed); err != nil {
+		return nil, fmt.Errorf("pairing: generate key: %w", err)
+	}
+
+	data, err = json.Marshal(keyFileJSON{SeedB64: base64.StdEncoding.EncodeToString(seed)})
+	if err != nil {
+		return nil, fmt.Errorf("pairing: marshal key file: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return nil, fmt.Errorf("pairing: write key file: %w", err)
+	}
+
+	return keyPairFromSeed(seed), nil
+}
+
+func keyPairFromSeed(seed []byte) *KeyPair {
+	priv := ed25519.NewKeyFromSeed(seed)
+	return &KeyPair{
+		Private: priv,
+		Public:  priv.Public().(ed25519.PublicKey),
+	}
+}
@@ -0,0 +1,34 @@
+package pairing
+
+import "path/filepath"
+
+// Manager mints and redeems enrollment tokens for one bridge's pairing
+// flow, backed by a persisted signing KeyPair and DeviceStore. One Manager
+// is shared across every bridge a daemon runs (see cmd/bridge), since
+// pairing state is daemon-wide rather than per-tenant.
+type Manager struct {
+	keys    *KeyPair
+	devices *DeviceStore
+	nonces  *nonceCache
+	pending *pendingTokens
+}
+
+// NewManager loads (or creates, on first run) the signing key and device
+// store under dir, which should be config.Dir().
+func NewManager(dir string) (*Manager, error) {
+	keys, err := LoadOrCreateKeyPair(dir)
+	if err != nil {
+		return nil, err
+	}
+	devices, err := NewDeviceStore(filepath.Join(dir, devicesFileName))
+	if err != nil {
+		return nil, err
+	}
+	return &Manager{keys: keys, devices: devices, nonces: newNonceCache(), pending: newPendingTokens()}, nil
+}
+
+// Devices exposes the underlying DeviceStore, for "pair list"/"pair revoke"
+// CLI subcommands.
+func (m *Manager) Devices() *DeviceStore {
+	return m.devices
+}
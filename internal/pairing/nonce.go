@@ -0,0 +1,47 @@
+package pairing
+
+import (
+	"sync"
+	"time"
+)
+
+// nonceTTL bounds how long claim remembers a redeemed nonce. It only needs
+// to outlive the longest token TTL a caller could reasonably mint, since a
+// token past its own Exp is already rejected before the nonce check runs.
+const nonceTTL = 10 * time.Minute
+
+// nonceCache remembers recently-redeemed token nonces in memory so
+// RedeemToken can reject replay of a captured QR. It's deliberately not
+// persisted: pendingTokens isn't either, so after a restart RedeemToken
+// rejects every token minted before it at the isCurrent check, before the
+// nonce is looked at.
+type nonceCache struct {
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+func newNonceCache() *nonceCache {
+	return &nonceCache{seen: make(map[string]time.Time)}
+}
+
+// claim reports whether nonce has not been seen before (and records it),
+// sweeping expired entries opportunistically so the map doesn't grow
+// unbounded across a long-running process.
+func (c *nonceCache) claim(nonce string) bool {
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for n, seenAt := range c.seen {
+		if now.Sub(seenAt) > nonceTTL {
+			delete(c.seen, n)
+		}
+	}
+
+	if _, ok := c.seen[nonce]; ok {
+		return false
+	}
+	c.seen[nonce] = now
+	return true
+}
@@ -0,0 +1,42 @@
+package pairing
+
+import "sync"
+
+// pendingTokens remembers, per UID, the nonce of the most recently minted
+// and not-yet-redeemed enrollment token. Minting a new token for a UID
+// supersedes any earlier one, so printing a fresh QR (see "openclaw-bridge
+// pair qr") revokes whatever unused token was in a previous screenshot
+// without needing to wait out its TTL.
+type pendingTokens struct {
+	mu         sync.Mutex
+	nonceByUID map[string]string
+}
+
+func newPendingTokens() *pendingTokens {
+	return &pendingTokens{nonceByUID: make(map[string]string)}
+}
+
+// set records nonce as the current outstanding token for uid, superseding
+// whatever nonce (if any) was previously pending for that uid.
+func (p *pendingTokens) set(uid, nonce string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.nonceByUID[uid] = nonce
+}
+
+// isCurrent reports whether nonce is still the current pending token for
+// uid - false if a later mint for the same uid has superseded it, or if it
+// was already redeemed and cleared.
+func (p *pendingTokens) isCurrent(uid, nonce string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.nonceByUID[uid] == nonce
+}
+
+// clear removes uid's pending entry once its token has been redeemed, so a
+// stale map entry doesn't outlive the token it describes.
+func (p *pendingTokens) clear(uid string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.nonceByUID, uid)
+}
@@ -0,0 +1,219 @@
+package pairing
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// DefaultTokenTTL is how long a minted enrollment token stays redeemable,
+// used by printConnectionQRCode unless a caller overrides it.
+const DefaultTokenTTL = 2 * time.Minute
+
+// hkdfInfo distinguishes this key-derivation purpose from any other future
+// use of the bridge's Ed25519 seed.
+const hkdfInfo = "openclaw-bridge-pairing-token-v1"
+
+// enrollmentPayload is the data a redeeming device needs, wrapped in
+// enrollmentEnvelope before encryption. Nonce prevents a captured-then-
+// replayed QR photo from being redeemed twice; Exp bounds how long it's
+// valid even if never redeemed.
+type enrollmentPayload struct {
+	WSURL string `json:"wsUrl"`
+	UID   string `json:"uid"`
+	Nonce string `json:"nonce"`
+	Exp   int64  `json:"exp"`
+}
+
+// enrollmentEnvelope pairs the payload with the bridge's signature over it,
+// so RedeemToken can authenticate the token before trusting its contents.
+type enrollmentEnvelope struct {
+	Payload enrollmentPayload `json:"payload"`
+	Sig     string            `json:"sig"`
+}
+
+// MintToken builds a compact, encrypted enrollment token embedding wsURL
+// and uid, valid for ttl. The token is what printConnectionQRCode puts in
+// the QR - unlike the raw wsUrl/uid pair it replaces, a photograph of it is
+// useless after ttl expires, after the first successful redeem, or once a
+// later MintToken call for the same uid supersedes it (see pendingTokens).
+func (m *Manager) MintToken(wsURL, uid string, ttl time.Duration) (string, error) {
+	nonce, err := randomNonce()
+	if err != nil {
+		return "", fmt.Errorf("pairing: generate nonce: %w", err)
+	}
+	m.pending.set(uid, nonce)
+
+	payload := enrollmentPayload{
+		WSURL: wsURL,
+		UID:   uid,
+		Nonce: nonce,
+		Exp:   time.Now().Add(ttl).Unix(),
+	}
+	payloadJSON, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("pairing: marshal payload: %w", err)
+	}
+
+	envelope := enrollmentEnvelope{
+		Payload: payload,
+		Sig:     base64.StdEncoding.EncodeToString(ed25519.Sign(m.keys.Private, payloadJSON)),
+	}
+	envelopeJSON, err := json.Marshal(envelope)
+	if err != nil {
+		return "", fmt.Errorf("pairing: marshal envelope: %w", err)
+	}
+
+	ciphertext, err := m.encrypt(envelopeJSON)
+	if err != nil {
+		return "", fmt.Errorf("pairing: encrypt token: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(ciphertext), nil
+}
+
+// RedeemToken decrypts and verifies tokenStr, rejecting it if expired,
+// already redeemed (nonce reuse), or tampered with. On success it registers
+// a new Device bound to devicePubKey and returns it along with the
+// long-term credential the caller should return to the device exactly
+// once - it is not recoverable from the stored Device afterwards.
+func (m *Manager) RedeemToken(tokenStr string, devicePubKey []byte) (*Device, string, error) {
+	ciphertext, err := base64.RawURLEncoding.DecodeString(tokenStr)
+	if err != nil {
+		return nil, "", fmt.Errorf("pairing: decode token: %w", err)
+	}
+
+	envelopeJSON, err := m.decrypt(ciphertext)
+	if err != nil {
+		return nil, "", fmt.Errorf("pairing: decrypt token: %w", err)
+	}
+
+	var envelope enrollmentEnvelope
+	if err := json.Unmarshal(envelopeJSON, &envelope); err != nil {
+		return nil, "", fmt.Errorf("pairing: parse token: %w", err)
+	}
+
+	payloadJSON, err := json.Marshal(envelope.Payload)
+	if err != nil {
+		return nil, "", fmt.Errorf("pairing: re-marshal payload: %w", err)
+	}
+	sig, err := base64.StdEncoding.DecodeString(envelope.Sig)
+	if err != nil || !ed25519.Verify(m.keys.Public, payloadJSON, sig) {
+		return nil, "", fmt.Errorf("pairing: invalid token signature")
+	}
+
+	if time.Now().Unix() > envelope.Payload.Exp {
+		return nil, "", fmt.Errorf("pairing: token expired")
+	}
+	if !m.pending.isCurrent(envelope.Payload.UID, envelope.Payload.Nonce) {
+		return nil, "", fmt.Errorf("pairing: token revoked by a newer pairing QR")
+	}
+	if !m.nonces.claim(envelope.Payload.Nonce) {
+		return nil, "", fmt.Errorf("pairing: token already redeemed")
+	}
+	m.pending.clear(envelope.Payload.UID)
+
+	credential, credentialHash, err := newCredential()
+	if err != nil {
+		return nil, "", fmt.Errorf("pairing: generate credential: %w", err)
+	}
+
+	device := &Device{
+		ID:             newDeviceID(),
+		PubKeyB64:      base64.StdEncoding.EncodeToString(devicePubKey),
+		UID:            envelope.Payload.UID,
+		WSURL:          envelope.Payload.WSURL,
+		CredentialHash: credentialHash,
+		PairedAt:       time.Now(),
+	}
+	if err := m.devices.Add(device); err != nil {
+		return nil, "", fmt.Errorf("pairing: save device: %w", err)
+	}
+
+	return device, credential, nil
+}
+
+// encrypt derives a one-off AES-256-GCM key via HKDF-SHA256 from the
+// bridge's signing seed and a random salt (stored alongside the
+// ciphertext, since HKDF salts aren't secret), then seals plaintext. The
+// wire format is salt || GCM-sealed(plaintext).
+func (m *Manager) encrypt(plaintext []byte) ([]byte, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	gcm, err := m.gcmFor(salt)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := gcm.Seal(nonce, nonce, plaintext, nil)
+	return append(salt, sealed...), nil
+}
+
+// decrypt is the inverse of encrypt.
+func (m *Manager) decrypt(data []byte) ([]byte, error) {
+	if len(data) < 16 {
+		return nil, fmt.Errorf("token too short")
+	}
+	salt, rest := data[:16], data[16:]
+	gcm, err := m.gcmFor(salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) < gcm.NonceSize() {
+		return nil, fmt.Errorf("token too short")
+	}
+	nonce, ciphertext := rest[:gcm.NonceSize()], rest[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+// gcmFor derives the AES-GCM cipher for a given HKDF salt from the bridge's
+// signing seed (the first 32 bytes of the Ed25519 private key).
+func (m *Manager) gcmFor(salt []byte) (cipher.AEAD, error) {
+	key := make([]byte, 32)
+	kdf := hkdf.New(sha256.New, m.keys.Private.Seed(), salt, []byte(hkdfInfo))
+	if _, err := io.ReadFull(kdf, key); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func randomNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// newCredential generates a long-term device credential, returning both the
+// plaintext (shared with the device exactly once) and its SHA-256 hash
+// (the only form persisted, so a stolen device store can't be replayed
+// directly as credentials).
+func newCredential() (plaintext, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	plaintext = base64.RawURLEncoding.EncodeToString(b)
+	sum := sha256.Sum256([]byte(plaintext))
+	hash = base64.RawURLEncoding.EncodeToString(sum[:])
+	return plaintext, hash, nil
+}
@@ -0,0 +1,96 @@
+package pairing
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestManager(t *testing.T) *Manager {
+	t.Helper()
+	m, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewManager() error = %v", err)
+	}
+	return m
+}
+
+func TestMintAndRedeemTokenRoundTrip(t *testing.T) {
+	m := newTestManager(t)
+
+	token, err := m.MintToken("ws://example.test/webhook", "uid-1", time.Minute)
+	if err != nil {
+		t.Fatalf("MintToken() error = %v", err)
+	}
+
+	device, credential, err := m.RedeemToken(token, []byte("device-pubkey"))
+	if err != nil {
+		t.Fatalf("RedeemToken() error = %v", err)
+	}
+	if device.UID != "uid-1" || device.WSURL != "ws://example.test/webhook" {
+		t.Fatalf("device = %+v, want uid-1/ws://example.test/webhook", device)
+	}
+	if credential == "" {
+		t.Fatal("RedeemToken() returned empty credential")
+	}
+}
+
+func TestRedeemTokenRejectsExpired(t *testing.T) {
+	m := newTestManager(t)
+
+	token, err := m.MintToken("ws://example.test/webhook", "uid-1", -time.Second)
+	if err != nil {
+		t.Fatalf("MintToken() error = %v", err)
+	}
+
+	if _, _, err := m.RedeemToken(token, []byte("device-pubkey")); err == nil {
+		t.Fatal("RedeemToken() on an expired token = nil error, want an error")
+	}
+}
+
+func TestRedeemTokenRejectsReplay(t *testing.T) {
+	m := newTestManager(t)
+
+	token, err := m.MintToken("ws://example.test/webhook", "uid-1", time.Minute)
+	if err != nil {
+		t.Fatalf("MintToken() error = %v", err)
+	}
+	if _, _, err := m.RedeemToken(token, []byte("device-pubkey")); err != nil {
+		t.Fatalf("first RedeemToken() error = %v", err)
+	}
+
+	if _, _, err := m.RedeemToken(token, []byte("device-pubkey")); err == nil {
+		t.Fatal("second RedeemToken() of the same token = nil error, want an error")
+	}
+}
+
+func TestMintTokenSupersedesPreviousPendingToken(t *testing.T) {
+	m := newTestManager(t)
+
+	oldToken, err := m.MintToken("ws://example.test/webhook", "uid-1", time.Minute)
+	if err != nil {
+		t.Fatalf("first MintToken() error = %v", err)
+	}
+	if _, err := m.MintToken("ws://example.test/webhook", "uid-1", time.Minute); err != nil {
+		t.Fatalf("second MintToken() error = %v", err)
+	}
+
+	if _, _, err := m.RedeemToken(oldToken, []byte("device-pubkey")); err == nil {
+		t.Fatal("RedeemToken() of a superseded token = nil error, want an error")
+	}
+}
+
+func TestMintTokenDoesNotSupersedeAcrossUIDs(t *testing.T) {
+	m := newTestManager(t)
+
+	tokenA, err := m.MintToken("ws://example.test/a", "uid-a", time.Minute)
+	if err != nil {
+		t.Fatalf("MintToken(uid-a) error = %v", err)
+	}
+	if _, err := m.MintToken("ws://example.test/b", "uid-b", time.Minute); err != nil {
+		t.Fatalf("MintToken(uid-b) error = %v", err)
+	}
+
+	if _, _, err := m.RedeemToken(tokenA, []byte("device-pubkey")); err != nil {
+		t.Fatalf("RedeemToken(uid-a's token) error = %v, want nil - a mint for a different uid shouldn't supersede it", err)
+	}
+}
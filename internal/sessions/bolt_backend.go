@@ -0,0 +1,309 @@
+package sessions
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"go.etcd.io/bbolt"
+)
+
+// BoltBackend is a Backend that stores sessions in an embedded bbolt
+// database: one bucket per agent ID (see bucketForKey), one key per session
+// key, SessionEntry JSON-encoded as the value. Unlike FileBackend it does
+// not rewrite the whole store on every mutation - UpdateEntry is a bounded
+// read-modify-write inside a single db.Update transaction, bbolt's own
+// single-writer MVCC gives per-key atomicity for free, and there's no
+// external .lock file or stale-lock heuristics to manage. It still requires
+// all bridge replicas to share one filesystem (like FileBackend); for
+// horizontally-scaled deployments use RedisBackend or EtcdBackend instead.
+type BoltBackend struct {
+	db *bbolt.DB
+
+	// cache mirrors FileBackend's copyStore-based read accelerator, but
+	// invalidates against bbolt's per-bucket sequence counters (bumped by
+	// every write in this backend) instead of file mtime.
+	cacheMu   sync.RWMutex
+	cache     map[string]*SessionEntry
+	cacheSeqs map[string]uint64
+}
+
+// NewBoltBackend opens (creating if necessary) the bbolt database at
+// config.BoltPath.
+func NewBoltBackend(config *StoreConfig) (*BoltBackend, error) {
+	if config.BoltPath == "" {
+		return nil, fmt.Errorf("bolt backend: BoltPath is required")
+	}
+	if dir := filepath.Dir(config.BoltPath); dir != "" && dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("bolt backend: create store directory: %w", err)
+		}
+	}
+
+	db, err := bbolt.Open(config.BoltPath, 0600, &bbolt.Options{Timeout: config.LockTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("bolt backend: open %s: %w", config.BoltPath, err)
+	}
+	return &BoltBackend{db: db}, nil
+}
+
+// bucketForKey derives the bucket a session key lives in from its agent ID
+// component (see ParseSessionKey), falling back to DefaultAgentID for keys
+// that don't carry one.
+func bucketForKey(sessionKey string) string {
+	agentID, _, _ := ParseSessionKey(sessionKey)
+	if agentID == "" {
+		agentID = DefaultAgentID
+	}
+	return agentID
+}
+
+// bucketSeqs snapshots every bucket's Sequence(), used to decide whether
+// the read cache is still valid.
+func (b *BoltBackend) bucketSeqs(tx *bbolt.Tx) map[string]uint64 {
+	seqs := make(map[string]uint64)
+	tx.ForEach(func(name []byte, bucket *bbolt.Bucket) error {
+		seqs[string(name)] = bucket.Sequence()
+		return nil
+	})
+	return seqs
+}
+
+func seqsEqual(a, b map[string]uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, seq := range a {
+		if b[name] != seq {
+			return false
+		}
+	}
+	return true
+}
+
+// Load returns a copy of the full session map, served from cache when no
+// bucket's sequence counter has moved since it was populated.
+func (b *BoltBackend) Load() (map[string]*SessionEntry, error) {
+	b.cacheMu.RLock()
+	cached, cachedSeqs := b.cache, b.cacheSeqs
+	b.cacheMu.RUnlock()
+
+	store := make(map[string]*SessionEntry)
+	var seqs map[string]uint64
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		seqs = b.bucketSeqs(tx)
+		if cached != nil && seqsEqual(cachedSeqs, seqs) {
+			return nil
+		}
+		return tx.ForEach(func(name []byte, bucket *bbolt.Bucket) error {
+			return bucket.ForEach(func(k, v []byte) error {
+				var entry SessionEntry
+				if err := json.Unmarshal(v, &entry); err != nil {
+					return fmt.Errorf("bolt backend: decode %s/%s: %w", name, k, err)
+				}
+				store[string(k)] = &entry
+				return nil
+			})
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if cached != nil && seqsEqual(cachedSeqs, seqs) {
+		return copySessionMap(cached), nil
+	}
+
+	b.cacheMu.Lock()
+	b.cache = copySessionMap(store)
+	b.cacheSeqs = seqs
+	b.cacheMu.Unlock()
+
+	return store, nil
+}
+
+// Save overwrites the full session map: buckets with no entries left in
+// store are dropped entirely, the rest are reconciled key by key.
+func (b *BoltBackend) Save(store map[string]*SessionEntry) error {
+	byBucket := make(map[string]map[string]*SessionEntry)
+	for key, entry := range store {
+		bucketName := bucketForKey(key)
+		if byBucket[bucketName] == nil {
+			byBucket[bucketName] = make(map[string]*SessionEntry)
+		}
+		byBucket[bucketName][key] = entry
+	}
+
+	err := b.db.Update(func(tx *bbolt.Tx) error {
+		var staleBuckets []string
+		if err := tx.ForEach(func(name []byte, _ *bbolt.Bucket) error {
+			if _, wanted := byBucket[string(name)]; !wanted {
+				staleBuckets = append(staleBuckets, string(name))
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+		for _, bucketName := range staleBuckets {
+			if err := tx.DeleteBucket([]byte(bucketName)); err != nil {
+				return fmt.Errorf("bolt backend: delete bucket %q: %w", bucketName, err)
+			}
+		}
+
+		for bucketName, entries := range byBucket {
+			bucket, err := tx.CreateBucketIfNotExists([]byte(bucketName))
+			if err != nil {
+				return fmt.Errorf("bolt backend: create bucket %q: %w", bucketName, err)
+			}
+
+			var staleKeys [][]byte
+			if err := bucket.ForEach(func(k, _ []byte) error {
+				if _, ok := entries[string(k)]; !ok {
+					staleKeys = append(staleKeys, append([]byte(nil), k...))
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+			for _, k := range staleKeys {
+				if err := bucket.Delete(k); err != nil {
+					return err
+				}
+			}
+
+			for key, entry := range entries {
+				payload, err := json.Marshal(entry)
+				if err != nil {
+					return fmt.Errorf("bolt backend: encode %q: %w", key, err)
+				}
+				if err := bucket.Put([]byte(key), payload); err != nil {
+					return err
+				}
+			}
+			if _, err := bucket.NextSequence(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	b.invalidateCache()
+	return nil
+}
+
+// Update loads the full session map, applies mutator, and saves the result.
+// Load and Save each run in their own bbolt transaction, so Update is not
+// atomic: a write that lands between them can be overwritten.
+func (b *BoltBackend) Update(mutator func(map[string]*SessionEntry) error) error {
+	store, err := b.Load()
+	if err != nil {
+		return err
+	}
+	if err := mutator(store); err != nil {
+		return err
+	}
+	return b.Save(store)
+}
+
+// GetEntry retrieves a single session entry, or nil if absent.
+func (b *BoltBackend) GetEntry(sessionKey string) (*SessionEntry, error) {
+	bucketName := bucketForKey(sessionKey)
+	var entry *SessionEntry
+	err := b.db.View(func(tx *bbolt.Tx) error {
+		bucket := tx.Bucket([]byte(bucketName))
+		if bucket == nil {
+			return nil
+		}
+		raw := bucket.Get([]byte(sessionKey))
+		if raw == nil {
+			return nil
+		}
+		var e SessionEntry
+		if err := json.Unmarshal(raw, &e); err != nil {
+			return fmt.Errorf("bolt backend: decode %q: %w", sessionKey, err)
+		}
+		entry = &e
+		return nil
+	})
+	return entry, err
+}
+
+// UpdateEntry atomically merges update's patch into the existing entry for
+// sessionKey inside a single db.Update transaction: a bounded
+// read-modify-write on one key, not the whole map.
+func (b *BoltBackend) UpdateEntry(sessionKey string, update func(*SessionEntry) (*SessionEntry, error)) (*SessionEntry, error) {
+	bucketName := bucketForKey(sessionKey)
+	var result *SessionEntry
+
+	err := b.db.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(bucketName))
+		if err != nil {
+			return fmt.Errorf("bolt backend: create bucket %q: %w", bucketName, err)
+		}
+
+		var existing *SessionEntry
+		if raw := bucket.Get([]byte(sessionKey)); raw != nil {
+			var entry SessionEntry
+			if err := json.Unmarshal(raw, &entry); err != nil {
+				return fmt.Errorf("bolt backend: decode %q: %w", sessionKey, err)
+			}
+			existing = &entry
+		}
+
+		patch, err := update(existing)
+		if err != nil {
+			return err
+		}
+		if patch == nil {
+			result = existing
+			return nil
+		}
+
+		merged := MergeSessionEntry(existing, patch)
+		payload, err := json.Marshal(merged)
+		if err != nil {
+			return fmt.Errorf("bolt backend: encode %q: %w", sessionKey, err)
+		}
+		if err := bucket.Put([]byte(sessionKey), payload); err != nil {
+			return err
+		}
+		if _, err := bucket.NextSequence(); err != nil {
+			return err
+		}
+		result = merged
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	b.invalidateCache()
+	return result, nil
+}
+
+// invalidateCache drops the read cache so the next Load re-snapshots bucket
+// sequences and reloads from disk.
+func (b *BoltBackend) invalidateCache() {
+	b.cacheMu.Lock()
+	b.cache = nil
+	b.cacheSeqs = nil
+	b.cacheMu.Unlock()
+}
+
+// copySessionMap creates a shallow copy of a session map, matching
+// FileBackend.copyStore: SessionEntry values are copied by value, nested
+// pointers (DeliveryContext) are shared since they're never mutated after
+// being stored.
+func copySessionMap(store map[string]*SessionEntry) map[string]*SessionEntry {
+	result := make(map[string]*SessionEntry, len(store))
+	for k, v := range store {
+		if v != nil {
+			copy := *v
+			result[k] = &copy
+		}
+	}
+	return result
+}
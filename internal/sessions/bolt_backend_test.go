@@ -0,0 +1,129 @@
+package sessions
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func newTestBoltBackend(t *testing.T) *BoltBackend {
+	t.Helper()
+	backend, err := NewBoltBackend(&StoreConfig{BoltPath: filepath.Join(t.TempDir(), "sessions.db")})
+	if err != nil {
+		t.Fatalf("NewBoltBackend() error = %v", err)
+	}
+	t.Cleanup(func() { backend.db.Close() })
+	return backend
+}
+
+func TestBoltBackendUpdateEntryCreatesAndMerges(t *testing.T) {
+	backend := newTestBoltBackend(t)
+
+	entry, err := backend.UpdateEntry("agent:main:webhook:1", func(existing *SessionEntry) (*SessionEntry, error) {
+		if existing != nil {
+			t.Fatalf("existing = %+v, want nil for a new key", existing)
+		}
+		return &SessionEntry{SessionID: "sess_1"}, nil
+	})
+	if err != nil {
+		t.Fatalf("UpdateEntry() error = %v", err)
+	}
+	if entry.SessionID != "sess_1" {
+		t.Fatalf("entry.SessionID = %q, want sess_1", entry.SessionID)
+	}
+
+	entry, err = backend.UpdateEntry("agent:main:webhook:1", func(existing *SessionEntry) (*SessionEntry, error) {
+		if existing == nil || existing.SessionID != "sess_1" {
+			t.Fatalf("existing = %+v, want the previously stored entry", existing)
+		}
+		return &SessionEntry{SystemSent: true}, nil
+	})
+	if err != nil {
+		t.Fatalf("second UpdateEntry() error = %v", err)
+	}
+	if entry.SessionID != "sess_1" || !entry.SystemSent {
+		t.Fatalf("merged entry = %+v, want SessionID preserved and SystemSent set", entry)
+	}
+}
+
+func TestBoltBackendGetEntryMissing(t *testing.T) {
+	backend := newTestBoltBackend(t)
+
+	entry, err := backend.GetEntry("agent:main:webhook:missing")
+	if err != nil {
+		t.Fatalf("GetEntry() error = %v", err)
+	}
+	if entry != nil {
+		t.Fatalf("GetEntry() = %+v, want nil for a key never written", entry)
+	}
+}
+
+func TestBoltBackendSaveAndLoadRoundTrip(t *testing.T) {
+	backend := newTestBoltBackend(t)
+
+	store := map[string]*SessionEntry{
+		"agent:main:webhook:1": {SessionID: "sess_1"},
+		"agent:main:webhook:2": {SessionID: "sess_2"},
+	}
+	if err := backend.Save(store); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := backend.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(loaded) != 2 || loaded["agent:main:webhook:1"].SessionID != "sess_1" || loaded["agent:main:webhook:2"].SessionID != "sess_2" {
+		t.Fatalf("Load() = %+v, want both saved entries", loaded)
+	}
+
+	delete(store, "agent:main:webhook:2")
+	if err := backend.Save(store); err != nil {
+		t.Fatalf("second Save() error = %v", err)
+	}
+	loaded, err = backend.Load()
+	if err != nil {
+		t.Fatalf("second Load() error = %v", err)
+	}
+	if len(loaded) != 1 || loaded["agent:main:webhook:2"] != nil {
+		t.Fatalf("Load() after drop = %+v, want only agent:main:webhook:1 left", loaded)
+	}
+}
+
+func TestBoltBackendLoadServesCacheUntilInvalidated(t *testing.T) {
+	backend := newTestBoltBackend(t)
+
+	if _, err := backend.UpdateEntry("agent:main:webhook:1", func(*SessionEntry) (*SessionEntry, error) {
+		return &SessionEntry{SessionID: "sess_1"}, nil
+	}); err != nil {
+		t.Fatalf("UpdateEntry() error = %v", err)
+	}
+
+	first, err := backend.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if backend.cache == nil {
+		t.Fatal("Load() did not populate the read cache")
+	}
+
+	second, err := backend.Load()
+	if err != nil {
+		t.Fatalf("second Load() error = %v", err)
+	}
+	if second["agent:main:webhook:1"].SessionID != first["agent:main:webhook:1"].SessionID {
+		t.Fatalf("second Load() = %+v, want it to match the cached snapshot", second)
+	}
+
+	if _, err := backend.UpdateEntry("agent:main:webhook:1", func(*SessionEntry) (*SessionEntry, error) {
+		return &SessionEntry{SystemSent: true}, nil
+	}); err != nil {
+		t.Fatalf("invalidating UpdateEntry() error = %v", err)
+	}
+	third, err := backend.Load()
+	if err != nil {
+		t.Fatalf("third Load() error = %v", err)
+	}
+	if !third["agent:main:webhook:1"].SystemSent {
+		t.Fatalf("Load() after a write = %+v, want the cache invalidated and the new value visible", third)
+	}
+}
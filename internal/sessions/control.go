@@ -17,14 +17,38 @@ const (
 	ControlMessageSessionReset ControlMessageType = "session.reset"
 	// ControlMessageSessionDelete deletes a session
 	ControlMessageSessionDelete ControlMessageType = "session.delete"
+	// ControlMessageSessionExpired notifies clients that a session expired
+	// server-side. It is only ever sent as a notification, never requested.
+	ControlMessageSessionExpired ControlMessageType = "session.expired"
+)
+
+// TransactionType tags a session control envelope so a client issuing
+// several concurrent requests (e.g. session.list and session.get) can tell
+// which response answers which request, and can distinguish both from an
+// unsolicited server notification.
+type TransactionType string
+
+const (
+	// TransactionTypeRequest marks an envelope as a request awaiting a
+	// correlated response. Older clients that omit TransactionType are
+	// still treated as requests for backward compatibility.
+	TransactionTypeRequest TransactionType = "request"
+	// TransactionTypeResponse marks an envelope as the answer to a request,
+	// correlated by RequestID.
+	TransactionTypeResponse TransactionType = "response"
+	// TransactionTypeNotification marks an envelope as server-initiated,
+	// with no corresponding request.
+	TransactionTypeNotification TransactionType = "notification"
 )
 
 // SessionControlMessage represents a session control message
 type SessionControlMessage struct {
-	Type   ControlMessageType `json:"type"`
-	Key    string            `json:"key,omitempty"`     // Session key
-	ID     string            `json:"id,omitempty"`      // Session ID (alternative to key)
-	Action string            `json:"action,omitempty"`  // Action to perform
+	Type            ControlMessageType `json:"type"`
+	RequestID       string             `json:"requestId"`                 // Correlates a response (and its request) across concurrent calls
+	TransactionType TransactionType    `json:"transactionType,omitempty"` // request/response/notification; empty is treated as a request
+	Key             string             `json:"key,omitempty"`             // Session key
+	ID              string             `json:"id,omitempty"`              // Session ID (alternative to key)
+	Action          string             `json:"action,omitempty"`          // Action to perform
 }
 
 // SessionInfoResponse contains session information
@@ -67,11 +91,32 @@ func ParseSessionControlMessage(data []byte) (*SessionControlMessage, error) {
 	return &msg, nil
 }
 
-// BuildSessionControlResponse builds a response for a session control message
-func BuildSessionControlResponse(msgType ControlMessageType, data interface{}) ([]byte, error) {
+// BuildSessionControlResponse builds a response for a session control
+// message, echoing requestID so the issuing client can correlate it with
+// the request that triggered it.
+func BuildSessionControlResponse(requestID string, msgType ControlMessageType, data interface{}) ([]byte, error) {
 	response := map[string]interface{}{
-		"type": msgType,
-		"data": data,
+		"type":            msgType,
+		"transactionType": TransactionTypeResponse,
+		"requestId":       requestID,
+		"data":            data,
 	}
 	return json.Marshal(response)
 }
+
+// BuildSessionNotification builds a server-initiated envelope with no
+// correlated request, e.g. a session.expired broadcast.
+func BuildSessionNotification(msgType ControlMessageType, data interface{}) ([]byte, error) {
+	response := map[string]interface{}{
+		"type":            msgType,
+		"transactionType": TransactionTypeNotification,
+		"data":            data,
+	}
+	return json.Marshal(response)
+}
+
+// IsResponse reports whether msg is answering a prior request rather than
+// issuing a new one.
+func (msg *SessionControlMessage) IsResponse() bool {
+	return msg.TransactionType == TransactionTypeResponse
+}
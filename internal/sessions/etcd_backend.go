@@ -0,0 +1,428 @@
+package sessions
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// etcdBackendKeyPrefix is the default EtcdBackend.keyPrefix.
+const etcdBackendKeyPrefix = "/openclaw/session/"
+
+// etcdEntryCASAttempts bounds the optimistic-concurrency retry loop in
+// EtcdBackend.UpdateEntry.
+const etcdEntryCASAttempts = 10
+
+// EtcdBackend is a Backend that stores each SessionEntry as its own etcd
+// key (keyPrefix+sessionKey), for multi-replica deployments that already
+// run etcd for coordination. It talks to etcd v3's JSON/gRPC-gateway HTTP
+// API (/v3/kv/...) rather than linking clientv3, keeping this dependency-
+// light like RedisBackend and the sync transports in sync_redis.go /
+// sync_nats.go. UpdateEntry uses a mod_revision compare-and-swap
+// transaction for per-key atomicity; Update/Save span multiple keys and
+// are therefore best-effort (not transactional) across the whole map.
+type EtcdBackend struct {
+	endpoint  string
+	keyPrefix string
+	client    *http.Client
+
+	// watchClient has no request timeout, unlike client: /v3/watch is a
+	// long-lived chunked stream, not a request/response round trip.
+	watchClient *http.Client
+
+	// Watch support: see changeHub. Lazily started on the first Watch call.
+	changeHub
+	watchOnce sync.Once
+	watchErr  error
+}
+
+// NewEtcdBackend builds an EtcdBackend from config. config.EtcdEndpoint is
+// required; config.EtcdKeyPrefix defaults to "/openclaw/session/".
+func NewEtcdBackend(config *StoreConfig) (*EtcdBackend, error) {
+	if config.EtcdEndpoint == "" {
+		return nil, fmt.Errorf("etcd backend: EtcdEndpoint is required")
+	}
+	prefix := config.EtcdKeyPrefix
+	if prefix == "" {
+		prefix = etcdBackendKeyPrefix
+	}
+	return &EtcdBackend{
+		endpoint:    strings.TrimRight(config.EtcdEndpoint, "/"),
+		keyPrefix:   prefix,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		watchClient: &http.Client{},
+	}, nil
+}
+
+func (b *EtcdBackend) key(sessionKey string) string {
+	return b.keyPrefix + sessionKey
+}
+
+// etcdKV is a single key/value/mod_revision tuple as returned by
+// /v3/kv/range and /v3/kv/txn, proto3-JSON-encoded (key/value are base64).
+type etcdKV struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	ModRevision string `json:"mod_revision"`
+}
+
+type etcdRangeResponse struct {
+	Kvs []etcdKV `json:"kvs"`
+}
+
+func (b *EtcdBackend) post(path string, body interface{}, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("etcd backend: encode request: %w", err)
+	}
+	resp, err := b.client.Post(b.endpoint+path, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("etcd backend: request %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("etcd backend: read response from %s: %w", path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("etcd backend: %s returned %s: %s", path, resp.Status, string(data))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("etcd backend: decode response from %s: %w", path, err)
+	}
+	return nil
+}
+
+// rangeGet fetches a single key, returning (nil, nil) if absent.
+func (b *EtcdBackend) rangeGet(fullKey string) (*etcdKV, error) {
+	var resp etcdRangeResponse
+	req := map[string]string{"key": base64.StdEncoding.EncodeToString([]byte(fullKey))}
+	if err := b.post("/v3/kv/range", req, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, nil
+	}
+	return &resp.Kvs[0], nil
+}
+
+// rangePrefix fetches every key under prefix, using the standard etcd
+// "prefix is [key, incremented-last-byte-of-key)" range_end trick.
+func (b *EtcdBackend) rangePrefix(prefix string) ([]etcdKV, error) {
+	var resp etcdRangeResponse
+	req := map[string]string{
+		"key":       base64.StdEncoding.EncodeToString([]byte(prefix)),
+		"range_end": base64.StdEncoding.EncodeToString([]byte(prefixRangeEnd(prefix))),
+	}
+	if err := b.post("/v3/kv/range", req, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Kvs, nil
+}
+
+// prefixRangeEnd computes the exclusive upper bound of the key range that
+// covers every key starting with prefix, per etcd's range_end convention.
+func prefixRangeEnd(prefix string) string {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return string(end[:i+1])
+		}
+	}
+	// prefix is all 0xff bytes: there is no finite upper bound.
+	return ""
+}
+
+func decodeEntry(kv *etcdKV) (*SessionEntry, error) {
+	if kv == nil {
+		return nil, nil
+	}
+	raw, err := base64.StdEncoding.DecodeString(kv.Value)
+	if err != nil {
+		return nil, fmt.Errorf("etcd backend: decode base64 value for %q: %w", kv.Key, err)
+	}
+	var entry SessionEntry
+	if err := json.Unmarshal(raw, &entry); err != nil {
+		return nil, fmt.Errorf("etcd backend: decode entry for %q: %w", kv.Key, err)
+	}
+	return &entry, nil
+}
+
+// GetEntry retrieves a single session entry, or nil if absent.
+func (b *EtcdBackend) GetEntry(sessionKey string) (*SessionEntry, error) {
+	kv, err := b.rangeGet(b.key(sessionKey))
+	if err != nil {
+		return nil, err
+	}
+	return decodeEntry(kv)
+}
+
+// Load returns every session entry under keyPrefix.
+func (b *EtcdBackend) Load() (map[string]*SessionEntry, error) {
+	kvs, err := b.rangePrefix(b.keyPrefix)
+	if err != nil {
+		return nil, err
+	}
+	store := make(map[string]*SessionEntry, len(kvs))
+	for i := range kvs {
+		kv := kvs[i]
+		entry, err := decodeEntry(&kv)
+		if err != nil || entry == nil {
+			continue
+		}
+		rawKey, err := base64.StdEncoding.DecodeString(kv.Key)
+		if err != nil {
+			continue
+		}
+		store[strings.TrimPrefix(string(rawKey), b.keyPrefix)] = entry
+	}
+	return store, nil
+}
+
+// Save overwrites the full session map: entries removed from store are
+// deleted, the rest are written. It is not transactional across keys;
+// UpdateEntry is the atomic per-key path.
+func (b *EtcdBackend) Save(store map[string]*SessionEntry) error {
+	existing, err := b.rangePrefix(b.keyPrefix)
+	if err != nil {
+		return err
+	}
+	for i := range existing {
+		kv := existing[i]
+		rawKey, err := base64.StdEncoding.DecodeString(kv.Key)
+		if err != nil {
+			continue
+		}
+		sessionKey := strings.TrimPrefix(string(rawKey), b.keyPrefix)
+		if _, ok := store[sessionKey]; ok {
+			continue
+		}
+		if err := b.post("/v3/kv/deleterange", map[string]string{"key": kv.Key}, nil); err != nil {
+			return err
+		}
+	}
+
+	for sessionKey, entry := range store {
+		payload, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("etcd backend: encode entry for %q: %w", sessionKey, err)
+		}
+		req := map[string]string{
+			"key":   base64.StdEncoding.EncodeToString([]byte(b.key(sessionKey))),
+			"value": base64.StdEncoding.EncodeToString(payload),
+		}
+		if err := b.post("/v3/kv/put", req, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Update loads the full map, applies mutator, and saves the result. Unlike
+// UpdateEntry this is not transactional: concurrent single-key writes from
+// other replicas between Load and Save can be overwritten.
+func (b *EtcdBackend) Update(mutator func(map[string]*SessionEntry) error) error {
+	store, err := b.Load()
+	if err != nil {
+		return err
+	}
+	if err := mutator(store); err != nil {
+		return err
+	}
+	return b.Save(store)
+}
+
+// UpdateEntry atomically merges update's patch into the existing entry
+// using an etcd compare-and-swap transaction: the PUT only commits if the
+// key's mod_revision still matches what we read, otherwise the txn's
+// "succeeded" field is false and we retry with a fresh read.
+func (b *EtcdBackend) UpdateEntry(sessionKey string, update func(*SessionEntry) (*SessionEntry, error)) (*SessionEntry, error) {
+	fullKey := b.key(sessionKey)
+
+	for attempt := 0; attempt < etcdEntryCASAttempts; attempt++ {
+		result, retry, err := b.tryUpdateEntry(fullKey, update)
+		if err != nil {
+			return nil, err
+		}
+		if !retry {
+			return result, nil
+		}
+	}
+	return nil, fmt.Errorf("etcd backend: UpdateEntry exhausted %d CAS attempts for %q", etcdEntryCASAttempts, sessionKey)
+}
+
+// etcdWatchEvent is a single entry in an etcdWatchResponse's events array.
+// Type is "PUT" (the zero value, since etcd omits it for puts) or "DELETE".
+type etcdWatchEvent struct {
+	Type string `json:"type"`
+	Kv   etcdKV `json:"kv"`
+}
+
+// etcdWatchResponse is one chunk of the newline-delimited JSON stream
+// returned by POST /v3/watch.
+type etcdWatchResponse struct {
+	Result struct {
+		Events []etcdWatchEvent `json:"events"`
+	} `json:"result"`
+}
+
+// Watch implements Watcher by opening a streaming /v3/watch request over
+// keyPrefix, etcd's HTTP/JSON gateway equivalent of clientv3.Watch. Like the
+// rest of this backend it avoids linking the clientv3 gRPC client; the
+// gateway gives the same create-watch-on-a-key-range semantics over plain
+// HTTP with a chunked, newline-delimited-JSON response body.
+func (b *EtcdBackend) Watch(ctx context.Context) (<-chan SessionChangeEvent, error) {
+	b.watchOnce.Do(func() {
+		b.watchErr = b.startWatching()
+	})
+	if b.watchErr != nil {
+		return nil, b.watchErr
+	}
+	return b.changeHub.subscribe(ctx), nil
+}
+
+func (b *EtcdBackend) startWatching() error {
+	req, err := b.watchRequest()
+	if err != nil {
+		return err
+	}
+	resp, err := b.watchClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("etcd backend: watch: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return fmt.Errorf("etcd backend: watch returned %s", resp.Status)
+	}
+	go b.watchLoop(resp.Body)
+	return nil
+}
+
+func (b *EtcdBackend) watchRequest() (*http.Request, error) {
+	body := map[string]interface{}{
+		"create_request": map[string]string{
+			"key":       base64.StdEncoding.EncodeToString([]byte(b.keyPrefix)),
+			"range_end": base64.StdEncoding.EncodeToString([]byte(prefixRangeEnd(b.keyPrefix))),
+		},
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("etcd backend: encode watch request: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, b.endpoint+"/v3/watch", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("etcd backend: build watch request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+func (b *EtcdBackend) watchLoop(body io.ReadCloser) {
+	defer body.Close()
+	decoder := json.NewDecoder(body)
+	for {
+		var resp etcdWatchResponse
+		if err := decoder.Decode(&resp); err != nil {
+			if err != io.EOF {
+				log.Printf("[EtcdBackend] Watch stream ended: %v", err)
+			}
+			return
+		}
+		for _, event := range resp.Result.Events {
+			b.handleWatchEvent(event)
+		}
+	}
+}
+
+func (b *EtcdBackend) handleWatchEvent(event etcdWatchEvent) {
+	rawKey, err := base64.StdEncoding.DecodeString(event.Kv.Key)
+	if err != nil {
+		return
+	}
+	sessionKey := strings.TrimPrefix(string(rawKey), b.keyPrefix)
+
+	if event.Type == "DELETE" {
+		b.changeHub.publish(SessionChangeEvent{Op: SessionChangeOpDelete, Key: sessionKey})
+		return
+	}
+
+	entry, err := decodeEntry(&event.Kv)
+	if err != nil || entry == nil {
+		return
+	}
+	b.changeHub.publish(SessionChangeEvent{Op: SessionChangeOpPut, Key: sessionKey, Entry: entry})
+}
+
+func (b *EtcdBackend) tryUpdateEntry(fullKey string, update func(*SessionEntry) (*SessionEntry, error)) (result *SessionEntry, retry bool, err error) {
+	kv, err := b.rangeGet(fullKey)
+	if err != nil {
+		return nil, false, err
+	}
+	existing, err := decodeEntry(kv)
+	if err != nil {
+		return nil, false, err
+	}
+
+	patch, err := update(existing)
+	if err != nil {
+		return nil, false, err
+	}
+	if patch == nil {
+		return existing, false, nil
+	}
+
+	merged := MergeSessionEntry(existing, patch)
+	payload, err := json.Marshal(merged)
+	if err != nil {
+		return nil, false, fmt.Errorf("etcd backend: encode entry for %q: %w", fullKey, err)
+	}
+
+	encodedKey := base64.StdEncoding.EncodeToString([]byte(fullKey))
+	modRevision := "0"
+	if kv != nil {
+		modRevision = kv.ModRevision
+	}
+
+	txn := map[string]interface{}{
+		"compare": []map[string]interface{}{
+			{
+				"key":          encodedKey,
+				"target":       "MOD",
+				"mod_revision": modRevision,
+			},
+		},
+		"success": []map[string]interface{}{
+			{
+				"request_put": map[string]string{
+					"key":   encodedKey,
+					"value": base64.StdEncoding.EncodeToString(payload),
+				},
+			},
+		},
+	}
+
+	var txnResp struct {
+		Succeeded bool `json:"succeeded"`
+	}
+	if err := b.post("/v3/kv/txn", txn, &txnResp); err != nil {
+		return nil, false, err
+	}
+	if !txnResp.Succeeded {
+		// The key changed between our read and the transaction: retry.
+		return nil, true, nil
+	}
+	return merged, false, nil
+}
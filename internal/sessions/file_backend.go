@@ -0,0 +1,690 @@
+package sessions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/gofrs/flock"
+)
+
+// FileBackend is the original Backend implementation: a single JSON file
+// with flock-based locking and an in-memory TTL cache. It requires all
+// bridge replicas to share one filesystem, so horizontally-scaled
+// deployments should use RedisBackend or EtcdBackend instead.
+type FileBackend struct {
+	config      *StoreConfig
+	cache       *StoreCache
+	cacheMu     sync.RWMutex
+	lockDir     string
+	enableCache bool
+
+	// Cached file mtime with periodic refresh
+	mtimeCache    int64
+	mtimeCacheMu  sync.RWMutex
+	mtimeCacheExp time.Time
+
+	// Watch support: changeHub fans out events to Watch callers, lazily
+	// started on the first call since most bridges never watch. watchMu
+	// guards watchSnapshot (the last map diffed against) and watchStarted.
+	changeHub
+	watchOnce     sync.Once
+	watchErr      error
+	watchMu       sync.Mutex
+	watchSnapshot map[string]*SessionEntry
+	watchStarted  bool
+
+	// UpdateEntry batching: a single writer goroutine coalesces pending
+	// mutations into one WAL append (see applyBatch) every FlushInterval, instead of
+	// taking the flock for every call. writerOnce lazily starts it on the
+	// first UpdateEntry since not every FileBackend sees concurrent writers.
+	writerOnce sync.Once
+	pendingCh  chan fileBackendWrite
+	flushCh    chan fileBackendFlush
+
+	// compactStop, when non-nil, stops the background goroutine that folds
+	// WAL records into the snapshot on config.CompactInterval (see wal.go
+	// and Close).
+	compactStop chan struct{}
+}
+
+// fileBackendWrite is one caller's UpdateEntry request, queued for the
+// writer goroutine to coalesce with others pending in the same flush cycle.
+type fileBackendWrite struct {
+	sessionKey string
+	update     func(*SessionEntry) (*SessionEntry, error)
+	resultCh   chan fileBackendResult
+}
+
+type fileBackendResult struct {
+	entry *SessionEntry
+	err   error
+}
+
+// fileBackendFlush is a request to coalesce and persist whatever is
+// currently queued, used by Flush to drain synchronously.
+type fileBackendFlush struct {
+	done chan struct{}
+}
+
+// StoreCache holds cached session data
+type StoreCache struct {
+	store     map[string]*SessionEntry
+	loadedAt  time.Time
+	mtimeMs   int64
+	validOnce bool
+}
+
+// NewFileBackend creates a new file-backed session store.
+func NewFileBackend(config *StoreConfig) *FileBackend {
+	if config == nil {
+		panic("config cannot be nil")
+	}
+
+	// Ensure store directory exists
+	storeDir := filepath.Dir(config.StorePath)
+	if err := os.MkdirAll(storeDir, 0755); err != nil {
+		log.Printf("[SessionStore] Failed to create store directory: %v", err)
+	}
+
+	// Lock directory in the same location as the store
+	lockDir := storeDir
+
+	backend := &FileBackend{
+		config:      config,
+		lockDir:     lockDir,
+		enableCache: config.CacheTTL > 0,
+	}
+	backend.startCompactor()
+	return backend
+}
+
+// startCompactor launches the goroutine that periodically folds the WAL
+// into a fresh snapshot via saveUnlocked, even when no Save/Update call
+// happens to trigger one - otherwise a bridge that only ever calls
+// UpdateEntry would grow the WAL file without bound.
+func (s *FileBackend) startCompactor() {
+	s.compactStop = make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(s.compactInterval())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.compact(); err != nil {
+					log.Printf("[SessionStore] WAL compaction failed: %v", err)
+				}
+			case <-s.compactStop:
+				return
+			}
+		}
+	}()
+}
+
+// compact folds any pending WAL records into the snapshot. It's the same
+// load-then-save sequence as Update, just without a caller-supplied
+// mutator.
+func (s *FileBackend) compact() error {
+	return s.withLock(func() error {
+		store, err := s.loadUnlocked()
+		if err != nil {
+			return err
+		}
+		return s.saveUnlocked(store)
+	})
+}
+
+// compactInterval is how often startCompactor folds the WAL into a fresh
+// snapshot; config.CompactInterval overrides the default.
+func (s *FileBackend) compactInterval() time.Duration {
+	if s.config.CompactInterval > 0 {
+		return s.config.CompactInterval
+	}
+	return 5 * time.Minute
+}
+
+// Close stops the background compaction goroutine started by NewFileBackend.
+// Store.Close calls this via an interface check alongside Flush.
+func (s *FileBackend) Close() error {
+	if s.compactStop != nil {
+		close(s.compactStop)
+	}
+	return nil
+}
+
+// Load loads the session store from disk (with cache support)
+func (s *FileBackend) Load() (map[string]*SessionEntry, error) {
+	// Check cache first
+	if s.enableCache {
+		s.cacheMu.RLock()
+		if s.cache != nil && s.isCacheValid(s.cache) {
+			// Check if file hasn't been modified (using cached mtime)
+			if s.getFileMtimeMsCached() == s.cache.mtimeMs {
+				// Return a copy for backward compatibility
+				result := s.copyStore(s.cache.store)
+				s.cacheMu.RUnlock()
+				log.Printf("[SessionStore] Loaded from cache (%d sessions)", len(result))
+				return result, nil
+			}
+		}
+		s.cacheMu.RUnlock()
+	}
+
+	// Load from disk
+	store := make(map[string]*SessionEntry)
+	data, err := os.ReadFile(s.config.StorePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read store: %w", err)
+	}
+	if err == nil {
+		if unmarshalErr := json.Unmarshal(data, &store); unmarshalErr != nil {
+			log.Printf("[SessionStore] Failed to parse store, starting fresh: %v", unmarshalErr)
+			store = make(map[string]*SessionEntry)
+		}
+	} else {
+		log.Printf("[SessionStore] No existing store, starting fresh")
+	}
+
+	if err := s.walReplay(store); err != nil {
+		return nil, err
+	}
+
+	// Update cache
+	if s.enableCache {
+		s.cacheMu.Lock()
+		s.cache = &StoreCache{
+			store:    s.copyStore(store),
+			loadedAt: time.Now(),
+			mtimeMs:  s.getFileMtimeMsCached(),
+		}
+		s.cacheMu.Unlock()
+	}
+
+	log.Printf("[SessionStore] Loaded from disk (%d sessions)", len(store))
+	return store, nil
+}
+
+// Save saves the session store to disk (with locking)
+func (s *FileBackend) Save(store map[string]*SessionEntry) error {
+	return s.withLock(func() error {
+		return s.saveUnlocked(store)
+	})
+}
+
+// Update atomically updates the session store
+func (s *FileBackend) Update(mutator func(map[string]*SessionEntry) error) error {
+	return s.withLock(func() error {
+		// Always re-read inside the lock to avoid clobbering concurrent writers
+		store, err := s.loadUnlocked()
+		if err != nil {
+			return err
+		}
+
+		if err := mutator(store); err != nil {
+			return err
+		}
+
+		return s.saveUnlocked(store)
+	})
+}
+
+// GetEntry retrieves a single session entry
+func (s *FileBackend) GetEntry(sessionKey string) (*SessionEntry, error) {
+	store, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+	return store[sessionKey], nil
+}
+
+// UpdateEntry queues a mutation for the batching writer goroutine (started
+// lazily on first use) instead of taking the flock itself: the writer
+// coalesces every request pending at the end of each FlushInterval tick
+// into a single loadUnlocked+walAppend pair. The call blocks until that
+// batch has actually been persisted, so callers still see synchronous
+// read-your-writes semantics - they just pay up to one FlushInterval of
+// extra latency in exchange for unrelated sessions no longer serializing on
+// the same flock.
+func (s *FileBackend) UpdateEntry(sessionKey string, update func(*SessionEntry) (*SessionEntry, error)) (*SessionEntry, error) {
+	s.writerOnce.Do(s.startWriter)
+
+	req := fileBackendWrite{
+		sessionKey: sessionKey,
+		update:     update,
+		resultCh:   make(chan fileBackendResult, 1),
+	}
+	s.pendingCh <- req
+	result := <-req.resultCh
+	return result.entry, result.err
+}
+
+// Flush coalesces and persists whatever is currently queued, blocking until
+// done. Tests and shutdown paths (see Store.Close) use this to drain the
+// batching writer synchronously instead of waiting out a FlushInterval.
+func (s *FileBackend) Flush() {
+	s.writerOnce.Do(s.startWriter)
+	done := make(chan struct{})
+	s.flushCh <- fileBackendFlush{done: done}
+	<-done
+}
+
+func (s *FileBackend) startWriter() {
+	s.pendingCh = make(chan fileBackendWrite, s.maxPendingWrites())
+	s.flushCh = make(chan fileBackendFlush)
+	go s.writeLoop()
+}
+
+// writeLoop is the single goroutine that owns every batched write: it
+// accumulates requests off pendingCh and flushes them as one applyBatch
+// call whenever flushInterval elapses or a manual Flush arrives.
+func (s *FileBackend) writeLoop() {
+	ticker := time.NewTicker(s.flushInterval())
+	defer ticker.Stop()
+
+	var batch []fileBackendWrite
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		s.applyBatch(batch)
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case req := <-s.pendingCh:
+			batch = append(batch, req)
+		case <-ticker.C:
+			flush()
+		case req := <-s.flushCh:
+			drainPending(&batch, s.pendingCh)
+			flush()
+			close(req.done)
+		}
+	}
+}
+
+// drainPending pulls every request currently buffered on ch into batch
+// without blocking, so a Flush call picks up writes already queued rather
+// than racing the next ticker-driven flush.
+func drainPending(batch *[]fileBackendWrite, ch chan fileBackendWrite) {
+	for {
+		select {
+		case req := <-ch:
+			*batch = append(*batch, req)
+		default:
+			return
+		}
+	}
+}
+
+// applyBatch loads the store once, applies every queued mutation against
+// it in order, and persists the result as WAL records (see wal.go) rather
+// than a full saveUnlocked rewrite - the periodic compaction started by
+// startCompactor (and any
+// Save/Update call) folds those records into the snapshot later. This is
+// what lets UpdateEntry avoid paying a full marshal-temp-rename cycle on
+// every FlushInterval tick under sustained write load.
+func (s *FileBackend) applyBatch(batch []fileBackendWrite) {
+	outcomes := make([]fileBackendResult, len(batch))
+
+	saveErr := s.withLock(func() error {
+		store, err := s.loadUnlocked()
+		if err != nil {
+			return err
+		}
+
+		var records []walRecord
+		for i, req := range batch {
+			existing := store[req.sessionKey]
+			patch, err := req.update(existing)
+			if err != nil {
+				outcomes[i] = fileBackendResult{err: err}
+				continue
+			}
+			if patch == nil {
+				outcomes[i] = fileBackendResult{entry: existing}
+				continue
+			}
+			merged := MergeSessionEntry(existing, patch)
+			store[req.sessionKey] = merged
+			outcomes[i] = fileBackendResult{entry: merged}
+			records = append(records, walRecord{Key: req.sessionKey, Entry: merged})
+		}
+
+		if err := s.walAppend(records); err != nil {
+			return err
+		}
+
+		s.cacheMu.Lock()
+		s.cache = nil
+		s.cacheMu.Unlock()
+
+		s.publishLocalChange(store)
+		return nil
+	})
+
+	for i, req := range batch {
+		if outcomes[i].err != nil {
+			req.resultCh <- fileBackendResult{err: outcomes[i].err}
+			continue
+		}
+		if saveErr != nil {
+			req.resultCh <- fileBackendResult{err: saveErr}
+			continue
+		}
+		req.resultCh <- fileBackendResult{entry: outcomes[i].entry}
+	}
+}
+
+// flushInterval is how often the writer goroutine coalesces pending writes;
+// config.FlushInterval overrides the default.
+func (s *FileBackend) flushInterval() time.Duration {
+	if s.config.FlushInterval > 0 {
+		return s.config.FlushInterval
+	}
+	return 50 * time.Millisecond
+}
+
+// maxPendingWrites bounds the pending-write queue; config.MaxPendingWrites
+// overrides the default. Once full, UpdateEntry blocks until the writer
+// catches up.
+func (s *FileBackend) maxPendingWrites() int {
+	if s.config.MaxPendingWrites > 0 {
+		return s.config.MaxPendingWrites
+	}
+	return 256
+}
+
+// Watch implements Watcher via fsnotify on the store directory: every
+// Write/Create/Rename event touching StorePath triggers a reload, which is
+// diffed against the last known snapshot to turn raw filesystem events into
+// SessionChangeEvents. saveUnlocked also publishes directly so a local
+// write is observed immediately rather than waiting on the fsnotify round
+// trip; the diff against watchSnapshot collapses the resulting fsnotify
+// event (once it arrives) to a no-op.
+func (s *FileBackend) Watch(ctx context.Context) (<-chan SessionChangeEvent, error) {
+	s.watchOnce.Do(func() {
+		s.watchErr = s.startWatching()
+	})
+	if s.watchErr != nil {
+		return nil, s.watchErr
+	}
+	return s.changeHub.subscribe(ctx), nil
+}
+
+func (s *FileBackend) startWatching() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("file backend: start watcher: %w", err)
+	}
+
+	storeDir := filepath.Dir(s.config.StorePath)
+	if err := watcher.Add(storeDir); err != nil {
+		watcher.Close()
+		return fmt.Errorf("file backend: watch %s: %w", storeDir, err)
+	}
+
+	if snapshot, err := s.loadUnlocked(); err == nil {
+		s.setWatchSnapshot(snapshot)
+	}
+
+	s.watchMu.Lock()
+	s.watchStarted = true
+	s.watchMu.Unlock()
+
+	go s.watchLoop(watcher)
+	return nil
+}
+
+func (s *FileBackend) watchLoop(watcher *fsnotify.Watcher) {
+	defer watcher.Close()
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != filepath.Clean(s.config.StorePath) {
+				continue
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) == 0 {
+				continue
+			}
+			store, err := s.loadUnlocked()
+			if err != nil {
+				log.Printf("[SessionStore] Watch: failed to reload store: %v", err)
+				continue
+			}
+			s.publishDiff(store)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Printf("[SessionStore] Watch: fsnotify error: %v", err)
+		}
+	}
+}
+
+// publishDiff compares store against the last known snapshot, publishes the
+// resulting events, and updates the snapshot.
+func (s *FileBackend) publishDiff(store map[string]*SessionEntry) {
+	s.watchMu.Lock()
+	prev := s.watchSnapshot
+	s.watchSnapshot = s.copyStore(store)
+	s.watchMu.Unlock()
+
+	for _, event := range diffSessionEntries(prev, store) {
+		s.changeHub.publish(event)
+	}
+}
+
+func (s *FileBackend) setWatchSnapshot(store map[string]*SessionEntry) {
+	s.watchMu.Lock()
+	s.watchSnapshot = s.copyStore(store)
+	s.watchMu.Unlock()
+}
+
+// publishLocalChange runs publishDiff for a write this process just made,
+// but only once a watcher has actually started - otherwise the first ever
+// save would diff against a nil snapshot and publish every entry as new.
+func (s *FileBackend) publishLocalChange(store map[string]*SessionEntry) {
+	s.watchMu.Lock()
+	started := s.watchStarted
+	s.watchMu.Unlock()
+	if !started {
+		return
+	}
+	s.publishDiff(store)
+}
+
+// loadUnlocked loads without cache checks (must be called with lock held)
+func (s *FileBackend) loadUnlocked() (map[string]*SessionEntry, error) {
+	store := make(map[string]*SessionEntry)
+	data, err := os.ReadFile(s.config.StorePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read store: %w", err)
+	}
+	if err == nil {
+		if unmarshalErr := json.Unmarshal(data, &store); unmarshalErr != nil {
+			log.Printf("[SessionStore] Failed to parse store: %v", unmarshalErr)
+			store = make(map[string]*SessionEntry)
+		}
+	}
+
+	if err := s.walReplay(store); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// saveUnlocked saves without locking (must be called with lock held)
+func (s *FileBackend) saveUnlocked(store map[string]*SessionEntry) error {
+	// Invalidate cache and mtime cache on write
+	s.cacheMu.Lock()
+	s.cache = nil
+	s.cacheMu.Unlock()
+
+	s.mtimeCacheMu.Lock()
+	s.mtimeCache = 0
+	s.mtimeCacheExp = time.Time{}
+	s.mtimeCacheMu.Unlock()
+
+	// Serialize
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal store: %w", err)
+	}
+
+	// Write atomically using temp file
+	tmpPath := s.config.StorePath + ".tmp"
+	tmpFile, err := os.OpenFile(tmpPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	// fsync before the rename, not after - the rename only needs to be
+	// atomic with respect to readers, but the data it points at needs to
+	// already be durable, or a crash between rename and a later fsync could
+	// leave the snapshot pointing at a truncated file.
+	if err := tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to fsync temp file: %w", err)
+	}
+	if err := tmpFile.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+
+	// Rename atomically
+	if err := os.Rename(tmpPath, s.config.StorePath); err != nil {
+		os.Remove(tmpPath) // Clean up temp file
+		return fmt.Errorf("failed to rename store file: %w", err)
+	}
+
+	// store now fully reflects every WAL record folded in by loadUnlocked,
+	// so the WAL is safe to drop - this is the "compaction" half of the
+	// WAL+snapshot scheme, and runs on every full rewrite (Save, Update,
+	// and the periodic compact), not just a dedicated pass.
+	if err := s.walTruncate(); err != nil {
+		log.Printf("[SessionStore] %v", err)
+	}
+
+	log.Printf("[SessionStore] Saved %d sessions", len(store))
+	s.publishLocalChange(store)
+	return nil
+}
+
+// isCacheValid checks if a cache entry is still valid
+func (s *FileBackend) isCacheValid(cache *StoreCache) bool {
+	if cache == nil {
+		return false
+	}
+	return time.Since(cache.loadedAt) < s.config.CacheTTL
+}
+
+// copyStore creates a shallow copy of the session store map
+// Individual SessionEntry values are copied by value (not deep cloned)
+// This is safe because SessionEntry contains only primitive types and pointers
+// that are never mutated after being stored
+func (s *FileBackend) copyStore(store map[string]*SessionEntry) map[string]*SessionEntry {
+	result := make(map[string]*SessionEntry, len(store))
+	for k, v := range store {
+		if v != nil {
+			// Shallow copy - copy the struct but not nested pointers
+			// Since DeliveryContext is the only nested pointer and we don't mutate it,
+			// this is safe for read-only access
+			copy := *v
+			result[k] = &copy
+		}
+	}
+	return result
+}
+
+// getFileMtimeMsCached gets the file modification time with caching
+// Cache expires after 1 second to reduce syscalls while staying fresh
+func (s *FileBackend) getFileMtimeMsCached() int64 {
+	s.mtimeCacheMu.RLock()
+	if time.Now().Before(s.mtimeCacheExp) && s.mtimeCache > 0 {
+		mtime := s.mtimeCache
+		s.mtimeCacheMu.RUnlock()
+		return mtime
+	}
+	s.mtimeCacheMu.RUnlock()
+
+	// Cache miss or expired, get fresh value
+	mtime := s.getFileMtimeMs()
+
+	s.mtimeCacheMu.Lock()
+	s.mtimeCache = mtime
+	s.mtimeCacheExp = time.Now().Add(time.Second)
+	s.mtimeCacheMu.Unlock()
+
+	return mtime
+}
+
+// getFileMtimeMs gets the file modification time in milliseconds
+func (s *FileBackend) getFileMtimeMs() int64 {
+	info, err := os.Stat(s.config.StorePath)
+	if err != nil {
+		return 0
+	}
+	return info.ModTime().UnixMilli()
+}
+
+// withLock executes a function with the store lock held using flock
+func (s *FileBackend) withLock(fn func() error) error {
+	lockPath := s.config.StorePath + ".lock"
+	timeout := s.config.LockTimeout
+
+	// Ensure lock directory exists
+	if err := os.MkdirAll(s.lockDir, 0755); err != nil {
+		return fmt.Errorf("failed to create lock directory: %w", err)
+	}
+
+	// Use flock for proper file locking
+	fileLock := flock.New(lockPath)
+
+	// Try to get lock with timeout using exponential backoff
+	startedAt := time.Now()
+	pollInterval := 25 * time.Millisecond
+	staleDuration := 30 * time.Second
+
+	for {
+		locked, err := fileLock.TryLock()
+		if err != nil {
+			return fmt.Errorf("lock error: %w", err)
+		}
+		if locked {
+			defer fileLock.Unlock()
+			// Check for stale lock info and log
+			if info, err := os.Stat(lockPath); err == nil {
+				age := time.Since(info.ModTime())
+				if age > staleDuration {
+					log.Printf("[SessionStore] Warning: lock file is %v old (may indicate crashed process)", age)
+				}
+			}
+			return fn()
+		}
+
+		// Check timeout
+		if time.Since(startedAt) > timeout {
+			return fmt.Errorf("timeout acquiring lock: %s", lockPath)
+		}
+
+		// Wait before retrying
+		time.Sleep(pollInterval)
+	}
+}
@@ -0,0 +1,55 @@
+package sessions
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func newTestFileBackend(t *testing.T) *FileBackend {
+	t.Helper()
+	backend := NewFileBackend(DefaultStoreConfig(filepath.Join(t.TempDir(), "sessions.json")))
+	t.Cleanup(func() { backend.Close() })
+	return backend
+}
+
+func TestFileBackendSaveRoundTripsThroughFsync(t *testing.T) {
+	backend := newTestFileBackend(t)
+
+	store := map[string]*SessionEntry{
+		"agent:main:webhook:1": {SessionID: "sess_1"},
+	}
+	if err := backend.Save(store); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := backend.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if entry := loaded["agent:main:webhook:1"]; entry == nil || entry.SessionID != "sess_1" {
+		t.Fatalf("loaded entry = %+v, want SessionID sess_1", entry)
+	}
+}
+
+func TestFileBackendUpdateEntrySurvivesWalReplay(t *testing.T) {
+	backend := newTestFileBackend(t)
+
+	if _, err := backend.UpdateEntry("agent:main:webhook:1", func(*SessionEntry) (*SessionEntry, error) {
+		return &SessionEntry{SessionID: "sess_1"}, nil
+	}); err != nil {
+		t.Fatalf("UpdateEntry() error = %v", err)
+	}
+
+	// A fresh backend over the same files has to replay the WAL record
+	// walAppend just fsynced, since nothing has triggered a compaction yet.
+	replayed := NewFileBackend(backend.config)
+	t.Cleanup(func() { replayed.Close() })
+
+	loaded, err := replayed.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if entry := loaded["agent:main:webhook:1"]; entry == nil || entry.SessionID != "sess_1" {
+		t.Fatalf("loaded entry = %+v, want SessionID sess_1 via wal replay", entry)
+	}
+}
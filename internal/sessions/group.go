@@ -0,0 +1,157 @@
+package sessions
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SessionGroup aggregates every SessionEntry sharing the same
+// (AgentID, PeerKind, PeerID) triple - the peer a webhook routes to -
+// regardless of which :topic: or :thread: sub-key BuildWebhookSessionKey
+// happened to mint for a particular message. The router can resolve a
+// logical conversation through it without caring which variant a given
+// webhook hit.
+type SessionGroup struct {
+	store   *Store
+	baseKey string
+	members map[string]*SessionEntry // keyed by full session key
+}
+
+// baseGroupKey reproduces BuildWebhookSessionKey's base key (agent +
+// peerKind + raw peerID, before any :topic: or :thread: suffix is
+// appended), which is what every member of the group has in common.
+func baseGroupKey(params WebhookSessionParams) (string, bool) {
+	peerKind := strings.ToLower(strings.TrimSpace(params.PeerKind))
+	peerID := strings.TrimSpace(params.PeerID)
+	if peerKind == "" || peerID == "" {
+		return "", false
+	}
+
+	switch peerKind {
+	case "dm", "group", "channel":
+	default:
+		return "", false
+	}
+
+	agentID := strings.TrimSpace(params.AgentID)
+	if agentID == "" {
+		agentID = DefaultAgentID
+	}
+
+	return NormalizeSessionKey(fmt.Sprintf("agent:%s:webhook:%s:%s", agentID, peerKind, peerID)), true
+}
+
+// belongsToGroup reports whether key is the group's bare base key or one of
+// its :topic:/:thread: sub-key variants.
+func belongsToGroup(key, baseKey string) bool {
+	if key == baseKey {
+		return true
+	}
+	return strings.HasPrefix(key, baseKey+":topic:") || strings.HasPrefix(key, baseKey+":thread:")
+}
+
+// LoadGroup loads every session entry belonging to the group identified by
+// params. It returns a group with no members (not an error) if none exist
+// yet - callers create the first one the normal way, via
+// RecordInboundMeta/UpdateLastRoute against a key from BuildWebhookSessionKey.
+func (s *Store) LoadGroup(params WebhookSessionParams) (*SessionGroup, error) {
+	baseKey, ok := baseGroupKey(params)
+	if !ok {
+		return nil, fmt.Errorf("sessions: LoadGroup requires PeerKind and PeerID")
+	}
+
+	all, err := s.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	members := make(map[string]*SessionEntry)
+	for key, entry := range all {
+		if entry != nil && belongsToGroup(key, baseKey) {
+			members[key] = entry
+		}
+	}
+
+	return &SessionGroup{store: s, baseKey: baseKey, members: members}, nil
+}
+
+// BaseKey returns the group's bare base key (no :topic:/:thread: suffix),
+// the session key a caller should route continuing messages to once
+// ShouldCollapseToMain reports the group collapses to one conversation.
+func (g *SessionGroup) BaseKey() string {
+	return g.baseKey
+}
+
+// Primary returns the group's canonical entry: the bare base key (no
+// :topic:/:thread: suffix) if one exists, otherwise the most recently
+// updated member. Returns nil for an empty group.
+func (g *SessionGroup) Primary() *SessionEntry {
+	if entry, ok := g.members[g.baseKey]; ok {
+		return entry
+	}
+	var primary *SessionEntry
+	for _, entry := range g.members {
+		if primary == nil || entry.UpdatedAt > primary.UpdatedAt {
+			primary = entry
+		}
+	}
+	return primary
+}
+
+// Members returns every entry in the group, ordered by session key for
+// deterministic output.
+func (g *SessionGroup) Members() []*SessionEntry {
+	keys := make([]string, 0, len(g.members))
+	for key := range g.members {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := make([]*SessionEntry, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, g.members[key])
+	}
+	return result
+}
+
+// ShouldCollapseToMain promotes ShouldCollapseToMain from a per-key
+// heuristic to a group-level decision: the group collapses to main unless
+// any member is a group/channel session (see IsGroupSessionKey).
+func (g *SessionGroup) ShouldCollapseToMain() bool {
+	for key := range g.members {
+		if IsGroupSessionKey(key) {
+			return false
+		}
+	}
+	return true
+}
+
+// UpdateAll applies mutator to every current member of the group under a
+// single Store.Update call rather than one UpdateEntry per member, so the
+// group patch is exactly as atomic as the backend's Update (see
+// Backend.Update): FileBackend holds its lock throughout, the others
+// load and save separately.
+// Membership is re-derived from the live map inside the Update, not from
+// the snapshot LoadGroup took, so a member added since LoadGroup is still
+// included. A nil patch leaves that member unchanged.
+func (g *SessionGroup) UpdateAll(mutator func(*SessionEntry) (*SessionEntry, error)) error {
+	return g.store.Update(func(all map[string]*SessionEntry) error {
+		for key, entry := range all {
+			if entry == nil || !belongsToGroup(key, g.baseKey) {
+				continue
+			}
+			patch, err := mutator(entry)
+			if err != nil {
+				return err
+			}
+			if patch == nil {
+				continue
+			}
+			merged := MergeSessionEntry(entry, patch)
+			all[key] = merged
+			g.members[key] = merged
+		}
+		return nil
+	})
+}
@@ -0,0 +1,127 @@
+package sessions
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestGroupStore(t *testing.T) *Store {
+	t.Helper()
+	store := NewStoreWithBackend(NewFileBackend(&StoreConfig{
+		StorePath:     filepath.Join(t.TempDir(), "sessions.json"),
+		FlushInterval: time.Millisecond,
+	}))
+	t.Cleanup(store.Close)
+	return store
+}
+
+func TestLoadGroupCollectsTopicVariants(t *testing.T) {
+	store := newTestGroupStore(t)
+
+	params := WebhookSessionParams{AgentID: "main", PeerKind: "group", PeerID: "room1"}
+	base, ok := baseGroupKey(params)
+	if !ok {
+		t.Fatalf("baseGroupKey(%+v) ok = false", params)
+	}
+	topicKey, ok := BuildWebhookSessionKey(WebhookSessionParams{AgentID: "main", PeerKind: "group", PeerID: "room1", TopicID: "t1"})
+	if !ok {
+		t.Fatalf("BuildWebhookSessionKey with topic ok = false")
+	}
+
+	if _, err := store.UpdateEntry(base, func(*SessionEntry) (*SessionEntry, error) {
+		return &SessionEntry{SessionID: "sess_base", UpdatedAt: 1}, nil
+	}); err != nil {
+		t.Fatalf("UpdateEntry(base) error = %v", err)
+	}
+	if _, err := store.UpdateEntry(topicKey, func(*SessionEntry) (*SessionEntry, error) {
+		return &SessionEntry{SessionID: "sess_topic", UpdatedAt: 2}, nil
+	}); err != nil {
+		t.Fatalf("UpdateEntry(topicKey) error = %v", err)
+	}
+	// An unrelated peer's session must not leak into the group.
+	if _, err := store.UpdateEntry("agent:main:webhook:group:other", func(*SessionEntry) (*SessionEntry, error) {
+		return &SessionEntry{SessionID: "sess_other"}, nil
+	}); err != nil {
+		t.Fatalf("UpdateEntry(other) error = %v", err)
+	}
+	store.Flush()
+
+	group, err := store.LoadGroup(params)
+	if err != nil {
+		t.Fatalf("LoadGroup() error = %v", err)
+	}
+	if group.BaseKey() != base {
+		t.Fatalf("BaseKey() = %q, want %q", group.BaseKey(), base)
+	}
+	if len(group.Members()) != 2 {
+		t.Fatalf("Members() = %v, want 2 entries", group.Members())
+	}
+	if primary := group.Primary(); primary == nil || primary.SessionID != "sess_base" {
+		t.Fatalf("Primary() = %+v, want the bare base key entry", primary)
+	}
+	if group.ShouldCollapseToMain() {
+		t.Fatalf("ShouldCollapseToMain() = true for a group session, want false")
+	}
+}
+
+func TestLoadGroupEmptyIsNotAnError(t *testing.T) {
+	store := newTestGroupStore(t)
+
+	group, err := store.LoadGroup(WebhookSessionParams{PeerKind: "dm", PeerID: "u1"})
+	if err != nil {
+		t.Fatalf("LoadGroup() on an empty store error = %v", err)
+	}
+	if len(group.Members()) != 0 {
+		t.Fatalf("Members() = %v, want none", group.Members())
+	}
+	if primary := group.Primary(); primary != nil {
+		t.Fatalf("Primary() = %+v, want nil for an empty group", primary)
+	}
+	if !group.ShouldCollapseToMain() {
+		t.Fatalf("ShouldCollapseToMain() = false for a dm session, want true")
+	}
+}
+
+func TestLoadGroupRequiresPeerKindAndID(t *testing.T) {
+	store := newTestGroupStore(t)
+	if _, err := store.LoadGroup(WebhookSessionParams{}); err == nil {
+		t.Fatalf("LoadGroup({}) error = nil, want an error")
+	}
+}
+
+func TestSessionGroupUpdateAllAppliesToEveryMember(t *testing.T) {
+	store := newTestGroupStore(t)
+	params := WebhookSessionParams{PeerKind: "channel", PeerID: "c1"}
+	base, _ := baseGroupKey(params)
+	topicKey, _ := BuildWebhookSessionKey(WebhookSessionParams{PeerKind: "channel", PeerID: "c1", TopicID: "t1"})
+
+	for _, key := range []string{base, topicKey} {
+		if _, err := store.UpdateEntry(key, func(*SessionEntry) (*SessionEntry, error) {
+			return &SessionEntry{SessionID: "sess_" + key}, nil
+		}); err != nil {
+			t.Fatalf("UpdateEntry(%q) error = %v", key, err)
+		}
+	}
+	store.Flush()
+
+	group, err := store.LoadGroup(params)
+	if err != nil {
+		t.Fatalf("LoadGroup() error = %v", err)
+	}
+	if err := group.UpdateAll(func(e *SessionEntry) (*SessionEntry, error) {
+		return &SessionEntry{LastChannel: "updated"}, nil
+	}); err != nil {
+		t.Fatalf("UpdateAll() error = %v", err)
+	}
+
+	for _, key := range []string{base, topicKey} {
+		entry, err := store.GetEntry(key)
+		if err != nil {
+			t.Fatalf("GetEntry(%q) error = %v", key, err)
+		}
+		if entry.LastChannel != "updated" {
+			t.Fatalf("GetEntry(%q).LastChannel = %q, want %q", key, entry.LastChannel, "updated")
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package sessions
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// IdempotencyConfig configures the bounded, TTL'd dedup window used by
+// IdempotencyStore.
+type IdempotencyConfig struct {
+	// TTL is how long a message ID is remembered before it can be reused.
+	TTL time.Duration
+	// MaxEntries bounds memory usage; the oldest entries are evicted first.
+	MaxEntries int
+}
+
+// DefaultIdempotencyConfig returns sane defaults for the dedup window.
+func DefaultIdempotencyConfig() *IdempotencyConfig {
+	return &IdempotencyConfig{
+		TTL:        10 * time.Minute,
+		MaxEntries: 2048,
+	}
+}
+
+// idempotencyEntry tracks a single in-flight or completed message.
+type idempotencyEntry struct {
+	response  []byte
+	expiresAt time.Time
+	order     uint64
+}
+
+// IdempotencyStore is a bounded, TTL'd dedup cache keyed by inbound message
+// ID (or an explicit Idempotency-Key). It lets callers detect a retried
+// webhook delivery and replay the response that was already emitted for it,
+// instead of forwarding a duplicate agent request.
+type IdempotencyStore struct {
+	mu      sync.Mutex
+	config  *IdempotencyConfig
+	entries map[string]*idempotencyEntry
+	seq     uint64
+}
+
+// NewIdempotencyStore creates a new idempotency store.
+func NewIdempotencyStore(config *IdempotencyConfig) *IdempotencyStore {
+	if config == nil {
+		config = DefaultIdempotencyConfig()
+	}
+	return &IdempotencyStore{
+		config:  config,
+		entries: make(map[string]*idempotencyEntry),
+	}
+}
+
+// Seen reports whether messageID has already been recorded within the TTL
+// window. When a response was previously remembered for it, that response
+// is returned so the caller can replay it.
+func (s *IdempotencyStore) Seen(messageID string) (response []byte, seen bool) {
+	if messageID == "" {
+		return nil, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[messageID]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(s.entries, messageID)
+		return nil, false
+	}
+	return entry.response, true
+}
+
+// Reserve marks messageID as in-flight so a concurrent retry is recognized
+// as a duplicate even before a response is available to replay.
+func (s *IdempotencyStore) Reserve(messageID string) {
+	if messageID == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.entries[messageID]; ok {
+		return
+	}
+	s.putLocked(messageID, nil)
+}
+
+// Remember records the response bytes that were emitted for messageID so a
+// later duplicate delivery can be replayed verbatim.
+func (s *IdempotencyStore) Remember(messageID string, response []byte) {
+	if messageID == "" {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.putLocked(messageID, response)
+}
+
+func (s *IdempotencyStore) putLocked(messageID string, response []byte) {
+	s.seq++
+	s.entries[messageID] = &idempotencyEntry{
+		response:  response,
+		expiresAt: time.Now().Add(s.config.TTL),
+		order:     s.seq,
+	}
+	s.evictLocked()
+}
+
+// evictLocked drops expired entries and, if still over MaxEntries, the
+// oldest remaining entries by insertion order. Must be called with mu held.
+func (s *IdempotencyStore) evictLocked() {
+	now := time.Now()
+	for id, entry := range s.entries {
+		if now.After(entry.expiresAt) {
+			delete(s.entries, id)
+		}
+	}
+
+	if s.config.MaxEntries <= 0 || len(s.entries) <= s.config.MaxEntries {
+		return
+	}
+
+	ids := make([]string, 0, len(s.entries))
+	for id := range s.entries {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return s.entries[ids[i]].order < s.entries[ids[j]].order
+	})
+
+	excess := len(s.entries) - s.config.MaxEntries
+	for i := 0; i < excess; i++ {
+		delete(s.entries, ids[i])
+	}
+}
@@ -0,0 +1,34 @@
+package sessions
+
+import "log"
+
+// MigrateFileStore imports session entries from the legacy JSON file at
+// oldPath into target, for bridges switching SessionStore.Driver away from
+// "file" (e.g. to "bolt" or "redis") after having run with the file backend
+// before. It's a no-op if oldPath doesn't exist (FileBackend.Load returns an
+// empty map for that case) or target already has entries - first-run
+// detection only, not an ongoing sync, so it never overwrites sessions the
+// new backend has already recorded.
+func MigrateFileStore(oldPath string, target Backend) (int, error) {
+	existing, err := target.Load()
+	if err != nil {
+		return 0, err
+	}
+	if len(existing) > 0 {
+		return 0, nil
+	}
+
+	legacy, err := NewFileBackend(&StoreConfig{StorePath: oldPath}).Load()
+	if err != nil {
+		return 0, err
+	}
+	if len(legacy) == 0 {
+		return 0, nil
+	}
+
+	if err := target.Save(legacy); err != nil {
+		return 0, err
+	}
+	log.Printf("[SessionStore] Migrated %d session(s) from %s", len(legacy), oldPath)
+	return len(legacy), nil
+}
@@ -0,0 +1,396 @@
+package sessions
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// redisBackendKeyPrefix is the default RedisBackend.keyPrefix.
+const redisBackendKeyPrefix = "openclaw:session:"
+
+// redisEntryCASAttempts bounds the optimistic-concurrency retry loop in
+// RedisBackend.UpdateEntry.
+const redisEntryCASAttempts = 10
+
+// RedisBackend is a Backend that stores each SessionEntry as its own Redis
+// key (keyPrefix+sessionKey), so several bridge replicas can share session
+// state instead of each needing its own session file. It speaks RESP
+// directly over net.Conn (see sync_redis.go), reusing the same
+// dependency-light approach as RedisTransport. UpdateEntry uses
+// WATCH/MULTI/EXEC for per-key atomicity; Update/Save span multiple keys
+// and are therefore best-effort (not transactional) across the whole map.
+type RedisBackend struct {
+	addr      string
+	keyPrefix string
+
+	// Watch support: see changeHub. Lazily started on the first Watch call
+	// over a dedicated PSUBSCRIBE connection to __keyevent@*__.
+	changeHub
+	watchOnce sync.Once
+	watchErr  error
+}
+
+// NewRedisBackend builds a RedisBackend from config. config.RedisAddr is
+// required; config.RedisKeyPrefix defaults to "openclaw:session:".
+func NewRedisBackend(config *StoreConfig) (*RedisBackend, error) {
+	if config.RedisAddr == "" {
+		return nil, fmt.Errorf("redis backend: RedisAddr is required")
+	}
+	prefix := config.RedisKeyPrefix
+	if prefix == "" {
+		prefix = redisBackendKeyPrefix
+	}
+	return &RedisBackend{addr: config.RedisAddr, keyPrefix: prefix}, nil
+}
+
+func (b *RedisBackend) key(sessionKey string) string {
+	return b.keyPrefix + sessionKey
+}
+
+func (b *RedisBackend) dial() (net.Conn, error) {
+	conn, err := net.DialTimeout("tcp", b.addr, 5*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("redis backend: dial %s: %w", b.addr, err)
+	}
+	return conn, nil
+}
+
+// GetEntry retrieves a single session entry, or nil if absent.
+func (b *RedisBackend) GetEntry(sessionKey string) (*SessionEntry, error) {
+	conn, err := b.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return b.getOnConn(conn, sessionKey)
+}
+
+func (b *RedisBackend) getOnConn(conn net.Conn, sessionKey string) (*SessionEntry, error) {
+	if err := respWriteCommand(conn, "GET", b.key(sessionKey)); err != nil {
+		return nil, err
+	}
+	reply, err := respReadReply(bufio.NewReader(conn))
+	if err != nil {
+		return nil, err
+	}
+	if reply == nil {
+		return nil, nil
+	}
+	payload, ok := reply.(string)
+	if !ok {
+		return nil, fmt.Errorf("redis backend: unexpected GET reply type %T", reply)
+	}
+	var entry SessionEntry
+	if err := json.Unmarshal([]byte(payload), &entry); err != nil {
+		return nil, fmt.Errorf("redis backend: decode entry for %q: %w", sessionKey, err)
+	}
+	return &entry, nil
+}
+
+// UpdateEntry atomically merges update's patch into the existing entry
+// using Redis's WATCH/MULTI/EXEC optimistic-concurrency pattern: a
+// concurrent write to the key between WATCH and EXEC aborts the
+// transaction (EXEC replies with a null array), and we retry.
+func (b *RedisBackend) UpdateEntry(sessionKey string, update func(*SessionEntry) (*SessionEntry, error)) (*SessionEntry, error) {
+	key := b.key(sessionKey)
+
+	for attempt := 0; attempt < redisEntryCASAttempts; attempt++ {
+		result, retry, err := b.tryUpdateEntry(key, update)
+		if err != nil {
+			return nil, err
+		}
+		if !retry {
+			return result, nil
+		}
+	}
+	return nil, fmt.Errorf("redis backend: UpdateEntry exhausted %d CAS attempts for %q", redisEntryCASAttempts, sessionKey)
+}
+
+func (b *RedisBackend) tryUpdateEntry(key string, update func(*SessionEntry) (*SessionEntry, error)) (result *SessionEntry, retry bool, err error) {
+	conn, err := b.dial()
+	if err != nil {
+		return nil, false, err
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	if err := respWriteCommand(conn, "WATCH", key); err != nil {
+		return nil, false, err
+	}
+	if _, err := respReadReply(reader); err != nil {
+		return nil, false, err
+	}
+
+	if err := respWriteCommand(conn, "GET", key); err != nil {
+		return nil, false, err
+	}
+	reply, err := respReadReply(reader)
+	if err != nil {
+		return nil, false, err
+	}
+	var existing *SessionEntry
+	if reply != nil {
+		payload, ok := reply.(string)
+		if !ok {
+			return nil, false, fmt.Errorf("redis backend: unexpected GET reply type %T", reply)
+		}
+		var entry SessionEntry
+		if err := json.Unmarshal([]byte(payload), &entry); err != nil {
+			return nil, false, fmt.Errorf("redis backend: decode entry for %q: %w", key, err)
+		}
+		existing = &entry
+	}
+
+	patch, err := update(existing)
+	if err != nil {
+		respWriteCommand(conn, "UNWATCH")
+		respReadReply(reader)
+		return nil, false, err
+	}
+	if patch == nil {
+		respWriteCommand(conn, "UNWATCH")
+		respReadReply(reader)
+		return existing, false, nil
+	}
+
+	merged := MergeSessionEntry(existing, patch)
+	payload, err := json.Marshal(merged)
+	if err != nil {
+		return nil, false, fmt.Errorf("redis backend: encode entry for %q: %w", key, err)
+	}
+
+	if err := respWriteCommand(conn, "MULTI"); err != nil {
+		return nil, false, err
+	}
+	if _, err := respReadReply(reader); err != nil {
+		return nil, false, err
+	}
+	if err := respWriteCommand(conn, "SET", key, string(payload)); err != nil {
+		return nil, false, err
+	}
+	if _, err := respReadReply(reader); err != nil { // QUEUED
+		return nil, false, err
+	}
+	if err := respWriteCommand(conn, "EXEC"); err != nil {
+		return nil, false, err
+	}
+	execReply, err := respReadReply(reader)
+	if err != nil {
+		return nil, false, err
+	}
+	if execReply == nil {
+		// Transaction aborted: the key changed between WATCH and EXEC.
+		return nil, true, nil
+	}
+	return merged, false, nil
+}
+
+// Load returns every session entry under keyPrefix.
+func (b *RedisBackend) Load() (map[string]*SessionEntry, error) {
+	conn, err := b.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	keys, err := b.scanKeys(conn, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	store := make(map[string]*SessionEntry, len(keys))
+	for _, fullKey := range keys {
+		if err := respWriteCommand(conn, "GET", fullKey); err != nil {
+			return nil, err
+		}
+		reply, err := respReadReply(reader)
+		if err != nil {
+			return nil, err
+		}
+		if reply == nil {
+			continue
+		}
+		payload, ok := reply.(string)
+		if !ok {
+			continue
+		}
+		var entry SessionEntry
+		if err := json.Unmarshal([]byte(payload), &entry); err != nil {
+			continue
+		}
+		store[fullKey[len(b.keyPrefix):]] = &entry
+	}
+	return store, nil
+}
+
+// scanKeys walks the keyspace with SCAN (instead of KEYS, which blocks the
+// server on large keyspaces) collecting every key under keyPrefix.
+func (b *RedisBackend) scanKeys(conn net.Conn, reader *bufio.Reader) ([]string, error) {
+	var keys []string
+	cursor := "0"
+	for {
+		if err := respWriteCommand(conn, "SCAN", cursor, "MATCH", b.keyPrefix+"*", "COUNT", "200"); err != nil {
+			return nil, err
+		}
+		reply, err := respReadReply(reader)
+		if err != nil {
+			return nil, err
+		}
+		fields, ok := reply.([]interface{})
+		if !ok || len(fields) != 2 {
+			return nil, fmt.Errorf("redis backend: unexpected SCAN reply shape")
+		}
+		cursor, _ = fields[0].(string)
+		if batch, ok := fields[1].([]interface{}); ok {
+			for _, item := range batch {
+				if s, ok := item.(string); ok {
+					keys = append(keys, s)
+				}
+			}
+		}
+		if cursor == "0" {
+			return keys, nil
+		}
+	}
+}
+
+// Save overwrites the full session map: entries removed from store are
+// deleted, the rest are written. It is not transactional across keys;
+// UpdateEntry is the atomic per-key path.
+func (b *RedisBackend) Save(store map[string]*SessionEntry) error {
+	conn, err := b.dial()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+
+	existingKeys, err := b.scanKeys(conn, reader)
+	if err != nil {
+		return err
+	}
+	for _, fullKey := range existingKeys {
+		sessionKey := fullKey[len(b.keyPrefix):]
+		if _, ok := store[sessionKey]; ok {
+			continue
+		}
+		if err := respWriteCommand(conn, "DEL", fullKey); err != nil {
+			return err
+		}
+		if _, err := respReadReply(reader); err != nil {
+			return err
+		}
+	}
+
+	for sessionKey, entry := range store {
+		payload, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("redis backend: encode entry for %q: %w", sessionKey, err)
+		}
+		if err := respWriteCommand(conn, "SET", b.key(sessionKey), string(payload)); err != nil {
+			return err
+		}
+		if _, err := respReadReply(reader); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Update loads the full map, applies mutator, and saves the result. Unlike
+// UpdateEntry this is not transactional: concurrent single-key writes from
+// other replicas between Load and Save can be overwritten.
+func (b *RedisBackend) Update(mutator func(map[string]*SessionEntry) error) error {
+	store, err := b.Load()
+	if err != nil {
+		return err
+	}
+	if err := mutator(store); err != nil {
+		return err
+	}
+	return b.Save(store)
+}
+
+// Watch implements Watcher via Redis keyspace notifications: it requires
+// the server to have notify-keyspace-events including key-event "set" and
+// "del"/"expired" notifications enabled (e.g. "notify-keyspace-events KEA"),
+// otherwise the PSUBSCRIBE succeeds but no message ever arrives. Every SET
+// or DEL against a key under keyPrefix - whether issued by this process's
+// own Save/UpdateEntry or by a peer replica - arrives as a key-event
+// notification, so unlike FileBackend there's no need to also publish
+// directly from Save/UpdateEntry.
+func (b *RedisBackend) Watch(ctx context.Context) (<-chan SessionChangeEvent, error) {
+	b.watchOnce.Do(func() {
+		b.watchErr = b.startWatching()
+	})
+	if b.watchErr != nil {
+		return nil, b.watchErr
+	}
+	return b.changeHub.subscribe(ctx), nil
+}
+
+func (b *RedisBackend) startWatching() error {
+	conn, err := b.dial()
+	if err != nil {
+		return fmt.Errorf("redis backend: watch dial: %w", err)
+	}
+	if err := respWriteCommand(conn, "PSUBSCRIBE", "__keyevent@*__:set", "__keyevent@*__:del", "__keyevent@*__:expired"); err != nil {
+		conn.Close()
+		return fmt.Errorf("redis backend: watch subscribe: %w", err)
+	}
+	go b.watchLoop(conn)
+	return nil
+}
+
+func (b *RedisBackend) watchLoop(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	for {
+		reply, err := respReadReply(reader)
+		if err != nil {
+			log.Printf("[RedisBackend] Watch subscription ended: %v", err)
+			return
+		}
+		fields, ok := reply.([]interface{})
+		if !ok || len(fields) < 4 {
+			continue
+		}
+		if kind, _ := fields[0].(string); kind != "pmessage" {
+			continue
+		}
+		pattern, _ := fields[1].(string)
+		fullKey, _ := fields[3].(string)
+		b.handleKeyEvent(pattern, fullKey)
+	}
+}
+
+func (b *RedisBackend) handleKeyEvent(pattern, fullKey string) {
+	if !strings.HasPrefix(fullKey, b.keyPrefix) {
+		return
+	}
+	sessionKey := fullKey[len(b.keyPrefix):]
+
+	if strings.HasSuffix(pattern, ":del") || strings.HasSuffix(pattern, ":expired") {
+		b.changeHub.publish(SessionChangeEvent{Op: SessionChangeOpDelete, Key: sessionKey})
+		return
+	}
+
+	entry, err := b.GetEntry(sessionKey)
+	if err != nil {
+		log.Printf("[RedisBackend] Watch: failed to fetch %q after set event: %v", sessionKey, err)
+		return
+	}
+	if entry == nil {
+		return
+	}
+	b.changeHub.publish(SessionChangeEvent{Op: SessionChangeOpPut, Key: sessionKey, Entry: entry})
+}
@@ -0,0 +1,154 @@
+package sessions
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// ResetMatcher decides whether a webhook message's content is a session
+// reset command. Implementations may strip the trigger from the content so
+// the remainder can still be forwarded as a normal message (e.g. in
+// prefix mode, "/reset hello" resets the session and forwards "hello").
+type ResetMatcher interface {
+	// Match reports whether content is a reset trigger. When matched is
+	// true, remainder is the content with the trigger removed (empty for a
+	// bare trigger, unchanged from content if the matcher doesn't support
+	// stripping).
+	Match(content string) (remainder string, matched bool)
+}
+
+// ResetTriggerConfig configures a PhraseMatcher. Phrases is keyed by
+// locale/language tag purely for organization in config files (e.g.
+// bridge.json's "triggers": {"en": ["new chat", "/reset"], "zh": ["重置"]});
+// all locales are matched regardless of the sender's actual language.
+type ResetTriggerConfig struct {
+	// Phrases maps a locale tag to literal trigger phrases for that locale.
+	Phrases map[string][]string
+	// Regexes are additional trigger patterns matched as regular
+	// expressions, independent of locale.
+	Regexes []string
+	// PrefixOnly allows a trigger to just lead the message (followed by
+	// whitespace or end-of-string) instead of requiring an exact match, and
+	// forwards the remainder as the new content. Off by default to match
+	// this bridge's historical exact-match behavior.
+	PrefixOnly bool
+}
+
+// DefaultResetTriggerConfig reproduces the bridge's original trigger set
+// ("/new", "/reset", exact match only) as a ResetTriggerConfig.
+func DefaultResetTriggerConfig() ResetTriggerConfig {
+	return ResetTriggerConfig{
+		Phrases: map[string][]string{"en": DefaultResetTriggers},
+	}
+}
+
+// PhraseMatcher is the default ResetMatcher: case-insensitive, Unicode-aware
+// matching against a flat list of trigger phrases (optionally per-locale)
+// plus optional regex triggers.
+type PhraseMatcher struct {
+	phrases    []string // normalized (case-folded) phrases, longest first
+	regexes    []*regexp.Regexp
+	prefixOnly bool
+}
+
+// NewPhraseMatcher builds a PhraseMatcher from cfg, compiling any regex
+// triggers. It returns an error if a regex fails to compile.
+func NewPhraseMatcher(cfg ResetTriggerConfig) (*PhraseMatcher, error) {
+	m := &PhraseMatcher{prefixOnly: cfg.PrefixOnly}
+
+	for _, phrases := range cfg.Phrases {
+		for _, phrase := range phrases {
+			normalized := normalizeTrigger(phrase)
+			if normalized != "" {
+				m.phrases = append(m.phrases, normalized)
+			}
+		}
+	}
+	// Try longer phrases first so "new chat please" isn't shadowed by a
+	// shorter overlapping trigger in prefix mode.
+	sortByLengthDesc(m.phrases)
+
+	for _, pattern := range cfg.Regexes {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("reset trigger regex %q: %w", pattern, err)
+		}
+		m.regexes = append(m.regexes, re)
+	}
+
+	return m, nil
+}
+
+// NewDefaultResetMatcher returns the preset matching this bridge's original
+// "/new" / "/reset" exact-match behavior.
+func NewDefaultResetMatcher() *PhraseMatcher {
+	m, _ := NewPhraseMatcher(DefaultResetTriggerConfig())
+	return m
+}
+
+// Match implements ResetMatcher.
+func (m *PhraseMatcher) Match(content string) (string, bool) {
+	normalized := normalizeTrigger(content)
+
+	for _, re := range m.regexes {
+		if m.prefixOnly {
+			if loc := re.FindStringIndex(content); loc != nil && loc[0] == 0 {
+				return strings.TrimLeft(content[loc[1]:], " "), true
+			}
+			continue
+		}
+		if re.MatchString(content) {
+			return "", true
+		}
+	}
+
+	for _, trigger := range m.phrases {
+		if !m.prefixOnly {
+			if normalized == trigger {
+				return "", true
+			}
+			continue
+		}
+		if normalized == trigger {
+			return "", true
+		}
+		if strings.HasPrefix(normalized, trigger+" ") {
+			offset := leadingSpaceLen(content) + len(trigger)
+			return strings.TrimLeft(content[offset:], " "), true
+		}
+	}
+
+	return content, false
+}
+
+// normalizeTrigger case-folds and trims content for trigger comparison. The
+// historical 100-char truncation is preserved so pathologically long
+// messages don't do unbounded work here.
+func normalizeTrigger(content string) string {
+	trimmed := strings.TrimSpace(content)
+	if len(trimmed) > 100 {
+		trimmed = trimmed[:100]
+	}
+	return strings.ToLower(trimmed)
+}
+
+// leadingSpaceLen returns the byte length of content's leading whitespace,
+// matching what strings.TrimSpace strips from the front. normalizeTrigger's
+// trigger-length offset is measured against its own TrimSpace'd copy of
+// content, so a caller slicing the raw (untrimmed) content by that same
+// offset must account for this leading run too.
+func leadingSpaceLen(content string) int {
+	return len(content) - len(strings.TrimLeftFunc(content, unicode.IsSpace))
+}
+
+// sortByLengthDesc sorts phrases longest-first in place (simple insertion
+// sort; trigger lists are tiny).
+func sortByLengthDesc(phrases []string) {
+	for i := 1; i < len(phrases); i++ {
+		for j := i; j > 0 && len(phrases[j]) > len(phrases[j-1]); j-- {
+			phrases[j], phrases[j-1] = phrases[j-1], phrases[j]
+		}
+	}
+}
@@ -1,37 +1,85 @@
 package sessions
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
+	"hash/fnv"
 	"log"
-	"os"
-	"path/filepath"
 	"sync"
 	"time"
 
-	"github.com/gofrs/flock"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/metrics"
 )
 
-// Store manages session persistence with in-memory caching and file locking
-type Store struct {
-	config      *StoreConfig
-	cache       *StoreCache
-	cacheMu     sync.RWMutex
-	lockDir     string
-	enableCache bool
+// expirySweepInterval is how often NewStore's background goroutine calls
+// Expire when config.TTL is set.
+const expirySweepInterval = 5 * time.Minute
+
+// lockShardCount is the size of Store.locks. UpdateEntry hashes sessionKey
+// into one of these shards so concurrent updates to unrelated sessions
+// don't serialize on a single in-process mutex the way FileBackend's single
+// flock serializes them at the OS level.
+const lockShardCount = 32
+
+// Backend is the storage interface behind Store: it owns durability and
+// concurrency control for the session map, while Store layers session-entry
+// semantics (merge-on-update, change notification) on top. FileBackend
+// (flock+JSON) is the original single-node implementation; BoltBackend
+// replaces its full-file-rewrite hot path with an embedded bbolt database
+// for single-node deployments that outgrow it; RedisBackend and EtcdBackend
+// let the bridge run with several replicas sharing session state instead of
+// forcing single-node operation.
+type Backend interface {
+	// Load returns a copy of the full session map.
+	Load() (map[string]*SessionEntry, error)
+	// Save overwrites the full session map.
+	Save(store map[string]*SessionEntry) error
+	// Update atomically applies mutator to the full session map. Backends
+	// that can't transact across arbitrary keys (Bolt, Redis, etcd) implement
+	// this as load-mutate-save; UpdateEntry is where they provide true
+	// per-key atomicity.
+	Update(mutator func(map[string]*SessionEntry) error) error
+	// GetEntry retrieves a single session entry, or nil if absent.
+	GetEntry(sessionKey string) (*SessionEntry, error)
+	// UpdateEntry atomically merges update's patch into the existing entry
+	// for sessionKey and persists the result.
+	UpdateEntry(sessionKey string, update func(*SessionEntry) (*SessionEntry, error)) (*SessionEntry, error)
+}
 
-	// Cached file mtime with periodic refresh
-	mtimeCache    int64
-	mtimeCacheMu  sync.RWMutex
-	mtimeCacheExp time.Time
+// Storage driver names for StoreConfig.Driver.
+const (
+	StoreDriverFile  = "file"
+	StoreDriverBolt  = "bolt"
+	StoreDriverRedis = "redis"
+	StoreDriverEtcd  = "etcd"
+)
+
+// Store manages session persistence on top of a pluggable Backend, adding
+// change notification for SyncService.
+type Store struct {
+	backend Backend
+
+	// changeListener, when set, is notified after every UpdateEntry-backed
+	// mutation so a SyncService can replicate it to other bridge replicas.
+	changeListenerMu sync.RWMutex
+	changeListener   func(key string, entry *SessionEntry)
+
+	// ttl/groupTTL mirror StoreConfig.TTL/GroupTTL; expireStop, when set,
+	// stops the background sweep goroutine started by NewStore.
+	ttl        time.Duration
+	groupTTL   time.Duration
+	expireStop chan struct{}
+
+	// locks shards UpdateEntry by sessionKey (see lockShardCount), taken
+	// before the call into backend.UpdateEntry.
+	locks [lockShardCount]sync.Mutex
 }
 
-// StoreCache holds cached session data
-type StoreCache struct {
-	store     map[string]*SessionEntry
-	loadedAt  time.Time
-	mtimeMs   int64
-	validOnce bool
+// lockFor returns the shard guarding sessionKey.
+func (s *Store) lockFor(sessionKey string) *sync.Mutex {
+	h := fnv.New32a()
+	h.Write([]byte(sessionKey))
+	return &s.locks[h.Sum32()%lockShardCount]
 }
 
 // ReadonlyStore provides a read-only view of the session store
@@ -40,77 +88,57 @@ type ReadonlyStore struct {
 	mu    sync.RWMutex
 }
 
-// NewStore creates a new session store
+// NewStore creates a session store backed by the driver named in
+// config.Driver ("file", "bolt", "redis", or "etcd"; empty defaults to "file").
 func NewStore(config *StoreConfig) *Store {
-	if config == nil {
-		panic("config cannot be nil")
-	}
-
-	// Ensure store directory exists
-	storeDir := filepath.Dir(config.StorePath)
-	if err := os.MkdirAll(storeDir, 0755); err != nil {
-		log.Printf("[SessionStore] Failed to create store directory: %v", err)
-	}
-
-	// Lock directory in the same location as the store
-	lockDir := storeDir
-
-	return &Store{
-		config:      config,
-		lockDir:     lockDir,
-		enableCache: config.CacheTTL > 0,
+	backend, err := newBackend(config)
+	if err != nil {
+		log.Fatalf("[SessionStore] Failed to initialize %q backend: %v", config.Driver, err)
 	}
+	store := &Store{backend: backend, ttl: config.TTL, groupTTL: config.GroupTTL}
+	store.startExpiryLoop()
+	return store
 }
 
-// Load loads the session store from disk (with cache support)
-// Returns a ReadonlyStore for efficient read-only access
-func (s *Store) Load() (map[string]*SessionEntry, error) {
-	// Check cache first
-	if s.enableCache {
-		s.cacheMu.RLock()
-		if s.cache != nil && s.isCacheValid(s.cache) {
-			// Check if file hasn't been modified (using cached mtime)
-			if s.getFileMtimeMsCached() == s.cache.mtimeMs {
-				// Return a copy for backward compatibility
-				result := s.copyStore(s.cache.store)
-				s.cacheMu.RUnlock()
-				log.Printf("[SessionStore] Loaded from cache (%d sessions)", len(result))
-				return result, nil
-			}
-		}
-		s.cacheMu.RUnlock()
-	}
+// NewStoreWithBackend wraps an already-constructed Backend, e.g. for tests
+// or callers that built a backend with custom connection options.
+func NewStoreWithBackend(backend Backend) *Store {
+	return &Store{backend: backend}
+}
 
-	// Load from disk
-	store := make(map[string]*SessionEntry)
-	data, err := os.ReadFile(s.config.StorePath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			// First run - return empty store
-			log.Printf("[SessionStore] No existing store, starting fresh")
-			return store, nil
-		}
-		return nil, fmt.Errorf("failed to read store: %w", err)
+func newBackend(config *StoreConfig) (Backend, error) {
+	if config == nil {
+		panic("config cannot be nil")
 	}
 
-	if err := json.Unmarshal(data, &store); err != nil {
-		log.Printf("[SessionStore] Failed to parse store, starting fresh: %v", err)
-		return make(map[string]*SessionEntry), nil
+	switch config.Driver {
+	case "", StoreDriverFile:
+		return NewFileBackend(config), nil
+	case StoreDriverBolt:
+		return NewBoltBackend(config)
+	case StoreDriverRedis:
+		return NewRedisBackend(config)
+	case StoreDriverEtcd:
+		return NewEtcdBackend(config)
+	default:
+		return nil, fmt.Errorf("unknown session store driver %q", config.Driver)
 	}
+}
 
-	// Update cache
-	if s.enableCache {
-		s.cacheMu.Lock()
-		s.cache = &StoreCache{
-			store:    s.copyStore(store),
-			loadedAt: time.Now(),
-			mtimeMs:  s.getFileMtimeMsCached(),
-		}
-		s.cacheMu.Unlock()
-	}
+// SetChangeListener registers fn to be called with the merged entry after
+// every successful UpdateEntry (and therefore RecordInboundMeta and
+// UpdateLastRoute, which are built on it). Pass nil to stop notifications.
+// ApplyExternalEntry deliberately bypasses this listener so applying a
+// replicated delta doesn't re-publish it.
+func (s *Store) SetChangeListener(fn func(key string, entry *SessionEntry)) {
+	s.changeListenerMu.Lock()
+	defer s.changeListenerMu.Unlock()
+	s.changeListener = fn
+}
 
-	log.Printf("[SessionStore] Loaded from disk (%d sessions)", len(store))
-	return store, nil
+// Load loads the full session store.
+func (s *Store) Load() (map[string]*SessionEntry, error) {
+	return s.backend.Load()
 }
 
 // LoadReadonly loads the session store and returns a read-only view
@@ -159,60 +187,117 @@ func (r *ReadonlyStore) All() map[string]*SessionEntry {
 	return result
 }
 
-// Save saves the session store to disk (with locking)
+// Save saves the session store.
 func (s *Store) Save(store map[string]*SessionEntry) error {
-	return s.withLock(func() error {
-		return s.saveUnlocked(store)
-	})
+	return s.backend.Save(store)
 }
 
-// Update atomically updates the session store
+// Update atomically updates the session store.
 func (s *Store) Update(mutator func(map[string]*SessionEntry) error) error {
-	return s.withLock(func() error {
-		// Always re-read inside the lock to avoid clobbering concurrent writers
-		store, err := s.loadUnlocked()
-		if err != nil {
-			return err
-		}
+	return s.backend.Update(mutator)
+}
 
-		if err := mutator(store); err != nil {
-			return err
+// GetEntry retrieves a single session entry, recording a
+// metrics.SessionStoreLookupsTotal hit/miss regardless of backend.
+func (s *Store) GetEntry(sessionKey string) (*SessionEntry, error) {
+	entry, err := s.backend.GetEntry(sessionKey)
+	if err == nil {
+		if entry != nil {
+			metrics.SessionStoreLookupsTotal.WithLabelValues("hit").Inc()
+		} else {
+			metrics.SessionStoreLookupsTotal.WithLabelValues("miss").Inc()
 		}
+	}
+	return entry, err
+}
+
+// UpdateEntry updates a single session entry. It takes the in-process
+// shard lock for sessionKey (see lockFor) before calling into the backend,
+// so concurrent updates to unrelated sessions don't wait on each other.
+func (s *Store) UpdateEntry(sessionKey string, update func(*SessionEntry) (*SessionEntry, error)) (*SessionEntry, error) {
+	lock := s.lockFor(sessionKey)
+	lock.Lock()
+	defer lock.Unlock()
 
-		return s.saveUnlocked(store)
+	result, err := s.backend.UpdateEntry(sessionKey, update)
+	if err == nil && result != nil {
+		s.notifyChange(sessionKey, result)
+	}
+	return result, err
+}
+
+// Get is an alias for GetEntry, for callers that prefer the shorter CRUD-ish
+// name.
+func (s *Store) Get(sessionKey string) (*SessionEntry, error) {
+	return s.GetEntry(sessionKey)
+}
+
+// Put replaces the entry for sessionKey outright, unlike Patch/UpdateEntry
+// which merge against whatever is already there via MergeSessionEntry.
+func (s *Store) Put(sessionKey string, entry *SessionEntry) error {
+	return s.Update(func(store map[string]*SessionEntry) error {
+		store[sessionKey] = entry
+		return nil
 	})
 }
 
-// GetEntry retrieves a single session entry
-func (s *Store) GetEntry(sessionKey string) (*SessionEntry, error) {
-	store, err := s.Load()
-	if err != nil {
-		return nil, err
-	}
-	return store[sessionKey], nil
+// Patch merges patch into the existing entry for sessionKey (creating it if
+// absent) via MergeSessionEntry, and notifies the change listener - it's
+// UpdateEntry with a fixed patch instead of a caller-supplied mutator.
+func (s *Store) Patch(sessionKey string, patch *SessionEntry) (*SessionEntry, error) {
+	return s.UpdateEntry(sessionKey, func(*SessionEntry) (*SessionEntry, error) {
+		return patch, nil
+	})
 }
 
-// UpdateEntry updates a single session entry
-func (s *Store) UpdateEntry(sessionKey string, update func(*SessionEntry) (*SessionEntry, error)) (*SessionEntry, error) {
-	var result *SessionEntry
-	err := s.Update(func(store map[string]*SessionEntry) error {
-		existing := store[sessionKey]
-		patch, err := update(existing)
-		if err != nil {
-			return err
-		}
-		if patch == nil {
-			result = existing
-			return nil
-		}
+// Delete removes the entry for sessionKey, if any.
+func (s *Store) Delete(sessionKey string) error {
+	return s.Update(func(store map[string]*SessionEntry) error {
+		delete(store, sessionKey)
+		return nil
+	})
+}
+
+// List is an alias for Load, for callers that prefer the shorter CRUD-ish
+// name.
+func (s *Store) List() (map[string]*SessionEntry, error) {
+	return s.Load()
+}
+
+// Flusher is an optional Backend capability: backends that batch or defer
+// writes (FileBackend) implement it so tests and shutdown paths can force
+// pending writes to disk before proceeding.
+type Flusher interface {
+	Flush()
+}
 
-		// Merge patch into existing
-		merged := MergeSessionEntry(existing, patch)
-		store[sessionKey] = merged
-		result = merged
+// Flush drains any pending writes on the backend, if it batches them (see
+// Flusher). It's a no-op for backends that already write synchronously.
+func (s *Store) Flush() {
+	if flusher, ok := s.backend.(Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// ApplyExternalEntry installs entry verbatim for sessionKey, as received
+// from a SyncService delta. Unlike UpdateEntry it does not merge against the
+// existing entry and does not notify the change listener, so applying a
+// replicated delta never re-publishes it back to the transport.
+func (s *Store) ApplyExternalEntry(sessionKey string, entry *SessionEntry) error {
+	return s.backend.Update(func(store map[string]*SessionEntry) error {
+		store[sessionKey] = entry
 		return nil
 	})
-	return result, err
+}
+
+// notifyChange invokes the registered change listener, if any.
+func (s *Store) notifyChange(sessionKey string, entry *SessionEntry) {
+	s.changeListenerMu.RLock()
+	listener := s.changeListener
+	s.changeListenerMu.RUnlock()
+	if listener != nil {
+		listener(sessionKey, entry)
+	}
 }
 
 // RecordInboundMeta records session metadata from an incoming webhook message
@@ -275,161 +360,6 @@ func (s *Store) UpdateLastRoute(sessionKey string, deliveryCtx *DeliveryContext)
 	})
 }
 
-// loadUnlocked loads without cache checks (must be called with lock held)
-func (s *Store) loadUnlocked() (map[string]*SessionEntry, error) {
-	store := make(map[string]*SessionEntry)
-	data, err := os.ReadFile(s.config.StorePath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return store, nil
-		}
-		return nil, fmt.Errorf("failed to read store: %w", err)
-	}
-
-	if err := json.Unmarshal(data, &store); err != nil {
-		log.Printf("[SessionStore] Failed to parse store: %v", err)
-		return make(map[string]*SessionEntry), nil
-	}
-
-	return store, nil
-}
-
-// saveUnlocked saves without locking (must be called with lock held)
-func (s *Store) saveUnlocked(store map[string]*SessionEntry) error {
-	// Invalidate cache and mtime cache on write
-	s.cacheMu.Lock()
-	s.cache = nil
-	s.cacheMu.Unlock()
-
-	s.mtimeCacheMu.Lock()
-	s.mtimeCache = 0
-	s.mtimeCacheExp = time.Time{}
-	s.mtimeCacheMu.Unlock()
-
-	// Serialize
-	data, err := json.MarshalIndent(store, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal store: %w", err)
-	}
-
-	// Write atomically using temp file
-	tmpPath := s.config.StorePath + ".tmp"
-	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
-		return fmt.Errorf("failed to write temp file: %w", err)
-	}
-
-	// Rename atomically
-	if err := os.Rename(tmpPath, s.config.StorePath); err != nil {
-		os.Remove(tmpPath) // Clean up temp file
-		return fmt.Errorf("failed to rename store file: %w", err)
-	}
-
-	log.Printf("[SessionStore] Saved %d sessions", len(store))
-	return nil
-}
-
-// isCacheValid checks if a cache entry is still valid
-func (s *Store) isCacheValid(cache *StoreCache) bool {
-	if cache == nil {
-		return false
-	}
-	return time.Since(cache.loadedAt) < s.config.CacheTTL
-}
-
-// copyStore creates a shallow copy of the session store map
-// Individual SessionEntry values are copied by value (not deep cloned)
-// This is safe because SessionEntry contains only primitive types and pointers
-// that are never mutated after being stored
-func (s *Store) copyStore(store map[string]*SessionEntry) map[string]*SessionEntry {
-	result := make(map[string]*SessionEntry, len(store))
-	for k, v := range store {
-		if v != nil {
-			// Shallow copy - copy the struct but not nested pointers
-			// Since DeliveryContext is the only nested pointer and we don't mutate it,
-			// this is safe for read-only access
-			copy := *v
-			result[k] = &copy
-		}
-	}
-	return result
-}
-
-// getFileMtimeMsCached gets the file modification time with caching
-// Cache expires after 1 second to reduce syscalls while staying fresh
-func (s *Store) getFileMtimeMsCached() int64 {
-	s.mtimeCacheMu.RLock()
-	if time.Now().Before(s.mtimeCacheExp) && s.mtimeCache > 0 {
-		mtime := s.mtimeCache
-		s.mtimeCacheMu.RUnlock()
-		return mtime
-	}
-	s.mtimeCacheMu.RUnlock()
-
-	// Cache miss or expired, get fresh value
-	mtime := s.getFileMtimeMs()
-
-	s.mtimeCacheMu.Lock()
-	s.mtimeCache = mtime
-	s.mtimeCacheExp = time.Now().Add(time.Second)
-	s.mtimeCacheMu.Unlock()
-
-	return mtime
-}
-
-// getFileMtimeMs gets the file modification time in milliseconds
-func (s *Store) getFileMtimeMs() int64 {
-	info, err := os.Stat(s.config.StorePath)
-	if err != nil {
-		return 0
-	}
-	return info.ModTime().UnixMilli()
-}
-
-// withLock executes a function with the store lock held using flock
-func (s *Store) withLock(fn func() error) error {
-	lockPath := s.config.StorePath + ".lock"
-	timeout := s.config.LockTimeout
-
-	// Ensure lock directory exists
-	if err := os.MkdirAll(s.lockDir, 0755); err != nil {
-		return fmt.Errorf("failed to create lock directory: %w", err)
-	}
-
-	// Use flock for proper file locking
-	fileLock := flock.New(lockPath)
-
-	// Try to get lock with timeout using exponential backoff
-	startedAt := time.Now()
-	pollInterval := 25 * time.Millisecond
-	staleDuration := 30 * time.Second
-
-	for {
-		locked, err := fileLock.TryLock()
-		if err != nil {
-			return fmt.Errorf("lock error: %w", err)
-		}
-		if locked {
-			defer fileLock.Unlock()
-			// Check for stale lock info and log
-			if info, err := os.Stat(lockPath); err == nil {
-				age := time.Since(info.ModTime())
-				if age > staleDuration {
-					log.Printf("[SessionStore] Warning: lock file is %v old (may indicate crashed process)", age)
-				}
-			}
-			return fn()
-		}
-
-		// Check timeout
-		if time.Since(startedAt) > timeout {
-			return fmt.Errorf("timeout acquiring lock: %s", lockPath)
-		}
-
-		// Wait before retrying
-		time.Sleep(pollInterval)
-	}
-}
-
 // Helper functions for delivery context
 func deliveryChannel(ctx *DeliveryContext) string {
 	if ctx != nil {
@@ -469,3 +399,99 @@ func generateSessionID() string {
 func GenerateSessionID() string {
 	return generateSessionID()
 }
+
+// startExpiryLoop launches the periodic sweep if ttl is configured; a
+// zero ttl leaves entries around forever, same as before TTL existed.
+func (s *Store) startExpiryLoop() {
+	if s.ttl <= 0 {
+		return
+	}
+	s.expireStop = make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(expirySweepInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.Expire(context.Background()); err != nil {
+					log.Printf("[SessionStore] Expire sweep failed: %v", err)
+				}
+			case <-s.expireStop:
+				return
+			}
+		}
+	}()
+}
+
+// Close stops the background expiry sweep started by NewStore, if TTL was
+// configured, and flushes any writes the backend has batched (see Flusher).
+// Safe to call even when TTL wasn't configured.
+func (s *Store) Close() {
+	if s.expireStop != nil {
+		close(s.expireStop)
+	}
+	s.Flush()
+	if closer, ok := s.backend.(interface{ Close() error }); ok {
+		if err := closer.Close(); err != nil {
+			log.Printf("[SessionStore] Error closing backend: %v", err)
+		}
+	}
+}
+
+// Expire deletes every session entry whose effective TTL (see expiresAt)
+// has passed, and logs a single structured summary of the sweep. It's a
+// no-op if neither TTL nor GroupTTL is configured. Safe to call manually
+// (e.g. from an admin endpoint) in addition to the background sweep.
+func (s *Store) Expire(ctx context.Context) error {
+	if s.ttl <= 0 && s.groupTTL <= 0 {
+		return nil
+	}
+
+	now := time.Now().UnixMilli()
+	var expired, remaining int
+	err := s.Update(func(store map[string]*SessionEntry) error {
+		for key, entry := range store {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+			if entry == nil || s.isExpired(key, entry, now) {
+				delete(store, key)
+				expired++
+				continue
+			}
+			remaining++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	log.Printf("[SessionStore] Expire sweep: expired=%d remaining=%d", expired, remaining)
+	return nil
+}
+
+// isExpired reports whether entry should be deleted by Expire: an explicit
+// ExpiresAt always wins, otherwise the entry expires ttlFor(key) after its
+// last UpdatedAt.
+func (s *Store) isExpired(key string, entry *SessionEntry, nowMs int64) bool {
+	if entry.ExpiresAt > 0 {
+		return entry.ExpiresAt < nowMs
+	}
+	ttl := s.ttlFor(key)
+	if ttl <= 0 {
+		return false
+	}
+	return entry.UpdatedAt > 0 && entry.UpdatedAt+ttl.Milliseconds() < nowMs
+}
+
+// ttlFor returns groupTTL for group/channel sessions (see
+// IsGroupSessionKey) when configured, and ttl otherwise - ephemeral
+// per-webhook sessions default to the shorter TTL.
+func (s *Store) ttlFor(key string) time.Duration {
+	if IsGroupSessionKey(key) && s.groupTTL > 0 {
+		return s.groupTTL
+	}
+	return s.ttl
+}
@@ -0,0 +1,88 @@
+package sessions
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStoreUpdateEntryShardsUnrelatedKeysConcurrently(t *testing.T) {
+	config := &StoreConfig{StorePath: filepath.Join(t.TempDir(), "store.json"), FlushInterval: time.Millisecond}
+	store := NewStoreWithBackend(NewFileBackend(config))
+	t.Cleanup(store.Close)
+
+	const keyCount = lockShardCount * 2
+	var wg sync.WaitGroup
+	for i := 0; i < keyCount; i++ {
+		key := fmt.Sprintf("agent:main:webhook:%d", i)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 20; n++ {
+				if _, err := store.UpdateEntry(key, func(existing *SessionEntry) (*SessionEntry, error) {
+					var count int64
+					if existing != nil {
+						count = existing.ExpiresAt // reuse an int64 field as a plain counter
+					}
+					return &SessionEntry{ExpiresAt: count + 1}, nil
+				}); err != nil {
+					t.Errorf("UpdateEntry(%s) error = %v", key, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	store.Flush()
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	for i := 0; i < keyCount; i++ {
+		key := fmt.Sprintf("agent:main:webhook:%d", i)
+		if entry := loaded[key]; entry == nil || entry.ExpiresAt != 20 {
+			t.Fatalf("entry for %s = %+v, want ExpiresAt 20 (20 serialized increments, no lost updates)", key, entry)
+		}
+	}
+}
+
+func TestStoreUpdateEntrySameKeySerializes(t *testing.T) {
+	config := &StoreConfig{StorePath: filepath.Join(t.TempDir(), "store.json"), FlushInterval: time.Millisecond}
+	store := NewStoreWithBackend(NewFileBackend(config))
+	t.Cleanup(store.Close)
+
+	const key = "agent:main:webhook:shared"
+	const writers = 16
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 10; n++ {
+				if _, err := store.UpdateEntry(key, func(existing *SessionEntry) (*SessionEntry, error) {
+					var count int64
+					if existing != nil {
+						count = existing.ExpiresAt
+					}
+					return &SessionEntry{ExpiresAt: count + 1}, nil
+				}); err != nil {
+					t.Errorf("UpdateEntry() error = %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	store.Flush()
+	entry, err := store.GetEntry(key)
+	if err != nil {
+		t.Fatalf("GetEntry() error = %v", err)
+	}
+	if entry == nil || entry.ExpiresAt != writers*10 {
+		t.Fatalf("entry = %+v, want ExpiresAt %d (every increment preserved, none clobbered)", entry, writers*10)
+	}
+}
@@ -0,0 +1,49 @@
+package sessions
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestNewBackendDispatchesOnDriver(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		config  *StoreConfig
+		want    string
+		wantErr bool
+	}{
+		{name: "empty defaults to file", config: &StoreConfig{StorePath: filepath.Join(dir, "a.json")}, want: "*sessions.FileBackend"},
+		{name: "file", config: &StoreConfig{Driver: StoreDriverFile, StorePath: filepath.Join(dir, "b.json")}, want: "*sessions.FileBackend"},
+		{name: "bolt", config: &StoreConfig{Driver: StoreDriverBolt, BoltPath: filepath.Join(dir, "c.db")}, want: "*sessions.BoltBackend"},
+		{name: "bolt missing path", config: &StoreConfig{Driver: StoreDriverBolt}, wantErr: true},
+		{name: "redis", config: &StoreConfig{Driver: StoreDriverRedis, RedisAddr: "127.0.0.1:6379"}, want: "*sessions.RedisBackend"},
+		{name: "redis missing addr", config: &StoreConfig{Driver: StoreDriverRedis}, wantErr: true},
+		{name: "etcd", config: &StoreConfig{Driver: StoreDriverEtcd, EtcdEndpoint: "http://127.0.0.1:2379"}, want: "*sessions.EtcdBackend"},
+		{name: "etcd missing endpoint", config: &StoreConfig{Driver: StoreDriverEtcd}, wantErr: true},
+		{name: "unknown driver", config: &StoreConfig{Driver: "carrier-pigeon"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend, err := newBackend(tt.config)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("newBackend(%q) error = nil, want an error", tt.config.Driver)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newBackend(%q) error = %v", tt.config.Driver, err)
+			}
+			if got := fmt.Sprintf("%T", backend); got != tt.want {
+				t.Fatalf("newBackend(%q) = %s, want %s", tt.config.Driver, got, tt.want)
+			}
+			if closer, ok := backend.(interface{ Close() error }); ok {
+				closer.Close()
+			}
+		})
+	}
+}
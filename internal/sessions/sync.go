@@ -0,0 +1,168 @@
+package sessions
+
+import (
+	"log"
+	"sync"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+)
+
+// SyncDelta is a single versioned session change replicated across bridge
+// replicas. Entry is the full post-merge entry (not a partial patch) so a
+// receiver can apply it directly with last-writer-wins semantics.
+type SyncDelta struct {
+	Key    string        `json:"key"`
+	Entry  *SessionEntry `json:"entry"`
+	Clock  uint64        `json:"clock"`
+	Origin string        `json:"origin"`
+}
+
+// SyncTransport is a pluggable pub/sub bus used to replicate session deltas
+// across bridge replicas behind a load balancer. Implementations include
+// Redis pub/sub (RedisTransport) and NATS (NatsTransport); InMemoryTransport
+// is a fake suitable for tests and single-process multi-bridge setups.
+type SyncTransport interface {
+	// Publish broadcasts a delta to every other subscriber.
+	Publish(delta SyncDelta) error
+	// Subscribe registers handler to be called for every delta received from
+	// other replicas. It must not block the caller.
+	Subscribe(handler func(SyncDelta)) error
+	// RequestSnapshot asks peers for their current session state so a fresh
+	// replica can catch up before serving traffic. It may return an empty,
+	// nil-error result if no peer answers in time.
+	RequestSnapshot() (map[string]*SessionEntry, error)
+	// ServeSnapshot registers a provider that answers peer snapshot requests
+	// with this replica's current state.
+	ServeSnapshot(provider func() map[string]*SessionEntry) error
+	Close() error
+}
+
+// SyncService keeps a local Store in sync with other bridge replicas over a
+// SyncTransport. Every UpdateEntry-backed mutation (RecordInboundMeta,
+// UpdateLastRoute, UpdateEntry itself) is published as a versioned delta;
+// inbound deltas are applied with last-writer-wins by logical clock. This
+// mirrors the store-sync service in the pb33f/ranch bus.
+type SyncService struct {
+	store     *Store
+	transport SyncTransport
+	origin    string
+	clock     uint64 // atomic, local logical clock
+
+	mu          sync.Mutex
+	remoteClock map[string]uint64 // last-applied (clock) per key, across all origins
+}
+
+// NewSyncService wires store to transport. Call Start to perform the initial
+// resync handshake and begin publishing/applying deltas.
+func NewSyncService(store *Store, transport SyncTransport) *SyncService {
+	return &SyncService{
+		store:       store,
+		transport:   transport,
+		origin:      uuid.NewString(),
+		remoteClock: make(map[string]uint64),
+	}
+}
+
+// Start performs the snapshot/resync handshake (so this replica catches up
+// with any state it missed while offline), then subscribes to inbound
+// deltas and registers store as the local change source.
+func (s *SyncService) Start() error {
+	if err := s.transport.ServeSnapshot(func() map[string]*SessionEntry {
+		snapshot, err := s.store.Load()
+		if err != nil {
+			log.Printf("[SessionSync] Failed to load snapshot for peer: %v", err)
+			return nil
+		}
+		return snapshot
+	}); err != nil {
+		return err
+	}
+
+	snapshot, err := s.transport.RequestSnapshot()
+	if err != nil {
+		log.Printf("[SessionSync] Snapshot resync failed, starting from local state: %v", err)
+	} else if len(snapshot) > 0 {
+		for key, entry := range snapshot {
+			s.applyExternal(SyncDelta{Key: key, Entry: entry, Clock: 0, Origin: "resync"})
+		}
+		log.Printf("[SessionSync] Resynced %d sessions from peers", len(snapshot))
+	}
+
+	if err := s.transport.Subscribe(s.applyExternal); err != nil {
+		return err
+	}
+
+	s.store.SetChangeListener(s.publishLocal)
+	log.Printf("[SessionSync] Started (origin=%s)", s.origin)
+	return nil
+}
+
+// publishLocal is invoked by Store after every successful UpdateEntry-backed
+// mutation and broadcasts it to other replicas.
+func (s *SyncService) publishLocal(key string, entry *SessionEntry) {
+	clock := atomic.AddUint64(&s.clock, 1)
+
+	s.mu.Lock()
+	s.remoteClock[key] = clock
+	s.mu.Unlock()
+
+	delta := SyncDelta{Key: key, Entry: entry, Clock: clock, Origin: s.origin}
+	if err := s.transport.Publish(delta); err != nil {
+		log.Printf("[SessionSync] Failed to publish delta for %q: %v", key, err)
+	}
+}
+
+// applyExternal applies an inbound delta with last-writer-wins semantics: it
+// is ignored if a newer (or equal, to avoid echo loops) clock has already
+// been applied for that key.
+func (s *SyncService) applyExternal(delta SyncDelta) {
+	if delta.Origin == s.origin {
+		return
+	}
+
+	if delta.Clock > 0 {
+		s.bumpClock(delta.Clock)
+	}
+
+	s.mu.Lock()
+	if delta.Clock > 0 && delta.Clock <= s.remoteClock[delta.Key] {
+		s.mu.Unlock()
+		log.Printf("[SessionSync] Dropped stale delta for %q (clock=%d, have=%d)", delta.Key, delta.Clock, s.remoteClock[delta.Key])
+		return
+	}
+	s.remoteClock[delta.Key] = delta.Clock
+	s.mu.Unlock()
+
+	if err := s.store.ApplyExternalEntry(delta.Key, delta.Entry); err != nil {
+		log.Printf("[SessionSync] Failed to apply delta for %q: %v", delta.Key, err)
+	}
+}
+
+// bumpClock applies the Lamport clock receive rule (local = max(local,
+// remote) + 1) so this replica's own counter - an independent atomic
+// counter per SyncService, not something shared with remote's origin -
+// never again issues a value at or below one a peer has already announced.
+// Without this, a replica that has done many local writes to unrelated
+// keys keeps a low counter relative to a busier peer, and a genuinely
+// newer update it later publishes for a shared key can be out-ranked (and
+// silently dropped) by that peer's stale-but-numerically-larger clock.
+func (s *SyncService) bumpClock(remoteClock uint64) {
+	for {
+		current := atomic.LoadUint64(&s.clock)
+		next := remoteClock
+		if current > next {
+			next = current
+		}
+		next++
+		if atomic.CompareAndSwapUint64(&s.clock, current, next) {
+			return
+		}
+	}
+}
+
+// Close stops replication and releases the underlying transport.
+func (s *SyncService) Close() error {
+	s.store.SetChangeListener(nil)
+	return s.transport.Close()
+}
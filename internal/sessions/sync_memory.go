@@ -0,0 +1,112 @@
+package sessions
+
+import "sync"
+
+// InMemoryTransport is a SyncTransport fake that fans deltas out to every
+// other InMemoryTransport sharing the same InMemoryBus. It's meant for
+// tests and single-process setups that run several bridges in-process; it
+// has no network dependency.
+type InMemoryTransport struct {
+	bus *InMemoryBus
+
+	mu       sync.RWMutex
+	handler  func(SyncDelta)
+	provider func() map[string]*SessionEntry
+}
+
+// InMemoryBus is the shared medium a set of InMemoryTransports publish to
+// and subscribe from. Create one per simulated "cluster".
+type InMemoryBus struct {
+	mu         sync.RWMutex
+	transports []*InMemoryTransport
+}
+
+// NewInMemoryBus creates an empty bus.
+func NewInMemoryBus() *InMemoryBus {
+	return &InMemoryBus{}
+}
+
+// NewTransport creates and registers a new transport on the bus.
+func (b *InMemoryBus) NewTransport() *InMemoryTransport {
+	t := &InMemoryTransport{bus: b}
+	b.mu.Lock()
+	b.transports = append(b.transports, t)
+	b.mu.Unlock()
+	return t
+}
+
+func (b *InMemoryBus) remove(t *InMemoryTransport) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, candidate := range b.transports {
+		if candidate == t {
+			b.transports = append(b.transports[:i], b.transports[i+1:]...)
+			break
+		}
+	}
+}
+
+// Publish implements SyncTransport.
+func (t *InMemoryTransport) Publish(delta SyncDelta) error {
+	t.bus.mu.RLock()
+	defer t.bus.mu.RUnlock()
+	for _, other := range t.bus.transports {
+		if other == t {
+			continue
+		}
+		other.mu.RLock()
+		handler := other.handler
+		other.mu.RUnlock()
+		if handler != nil {
+			handler(delta)
+		}
+	}
+	return nil
+}
+
+// Subscribe implements SyncTransport.
+func (t *InMemoryTransport) Subscribe(handler func(SyncDelta)) error {
+	t.mu.Lock()
+	t.handler = handler
+	t.mu.Unlock()
+	return nil
+}
+
+// RequestSnapshot implements SyncTransport by asking every peer transport on
+// the bus for its snapshot and merging them (last one wins; this transport
+// is only meant for tests where a single peer usually answers).
+func (t *InMemoryTransport) RequestSnapshot() (map[string]*SessionEntry, error) {
+	t.bus.mu.RLock()
+	defer t.bus.mu.RUnlock()
+
+	merged := make(map[string]*SessionEntry)
+	for _, other := range t.bus.transports {
+		if other == t {
+			continue
+		}
+		other.mu.RLock()
+		provider := other.provider
+		other.mu.RUnlock()
+		if provider == nil {
+			continue
+		}
+		for key, entry := range provider() {
+			merged[key] = entry
+		}
+	}
+	return merged, nil
+}
+
+// ServeSnapshot implements SyncTransport.
+func (t *InMemoryTransport) ServeSnapshot(provider func() map[string]*SessionEntry) error {
+	t.mu.Lock()
+	t.provider = provider
+	t.mu.Unlock()
+	return nil
+}
+
+// Close implements SyncTransport.
+func (t *InMemoryTransport) Close() error {
+	t.bus.remove(t)
+	return nil
+}
@@ -0,0 +1,286 @@
+package sessions
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NatsTransport is a SyncTransport backed by NATS core pub/sub. Like
+// RedisTransport it speaks the (very small) wire protocol directly over
+// net.Conn instead of depending on a client library, to keep this package's
+// dependency footprint in line with the rest of the repo.
+type NatsTransport struct {
+	addr    string
+	subject string
+	inbox   string
+
+	mu   sync.Mutex
+	conn net.Conn
+
+	handler  func(SyncDelta)
+	provider func() map[string]*SessionEntry
+
+	pendingSnapshot chan map[string]*SessionEntry
+
+	closed chan struct{}
+}
+
+// NewNatsTransport connects to a NATS server at addr (host:port) and
+// replicates deltas over subject.
+func NewNatsTransport(addr, subject string) (*NatsTransport, error) {
+	t := &NatsTransport{
+		addr:    addr,
+		subject: subject,
+		inbox:   subject + ".inbox." + uuid.NewString(),
+		closed:  make(chan struct{}),
+	}
+
+	conn, err := t.dial()
+	if err != nil {
+		return nil, err
+	}
+	t.conn = conn
+	return t, nil
+}
+
+func (t *NatsTransport) snapshotRequestSubject() string { return t.subject + ".snapshot-request" }
+
+func (t *NatsTransport) dial() (net.Conn, error) {
+	conn, err := net.DialTimeout("tcp", t.addr, 5*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("nats transport: dial %s: %w", t.addr, err)
+	}
+	// Discard the server's INFO banner, then send a minimal CONNECT.
+	reader := bufio.NewReader(conn)
+	if _, err := reader.ReadString('\n'); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("nats transport: read INFO: %w", err)
+	}
+	if _, err := conn.Write([]byte("CONNECT {\"verbose\":false}\r\n")); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// Publish implements SyncTransport.
+func (t *NatsTransport) Publish(delta SyncDelta) error {
+	payload, err := json.Marshal(delta)
+	if err != nil {
+		return fmt.Errorf("nats transport: marshal delta: %w", err)
+	}
+	return t.publishRaw(t.subject, payload)
+}
+
+func (t *NatsTransport) publishRaw(subject string, payload []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	frame := fmt.Sprintf("PUB %s %d\r\n", subject, len(payload))
+	if _, err := t.conn.Write([]byte(frame)); err != nil {
+		return err
+	}
+	if _, err := t.conn.Write(payload); err != nil {
+		return err
+	}
+	_, err := t.conn.Write([]byte("\r\n"))
+	return err
+}
+
+// Subscribe implements SyncTransport. It opens a dedicated connection for
+// the read loop (PUB/SUB share a connection in NATS, but this keeps the
+// same publish/subscribe split as RedisTransport and survives reconnects
+// without disturbing in-flight publishes).
+func (t *NatsTransport) Subscribe(handler func(SyncDelta)) error {
+	t.handler = handler
+	go t.subscribeLoop()
+	return nil
+}
+
+func (t *NatsTransport) subscribeLoop() {
+	backoff := 500 * time.Millisecond
+	const maxBackoff = 30 * time.Second
+
+	for {
+		select {
+		case <-t.closed:
+			return
+		default:
+		}
+
+		conn, err := t.dial()
+		if err != nil {
+			log.Printf("[NatsTransport] Subscribe dial failed, retrying in %v: %v", backoff, err)
+			time.Sleep(backoff)
+			if backoff < maxBackoff {
+				backoff *= 2
+			}
+			continue
+		}
+		backoff = 500 * time.Millisecond
+
+		if err := t.runSubscription(conn); err != nil {
+			log.Printf("[NatsTransport] Subscription ended, reconnecting: %v", err)
+		}
+		conn.Close()
+	}
+}
+
+func (t *NatsTransport) runSubscription(conn net.Conn) error {
+	if _, err := conn.Write([]byte(fmt.Sprintf("SUB %s 1\r\n", t.subject))); err != nil {
+		return err
+	}
+	if _, err := conn.Write([]byte(fmt.Sprintf("SUB %s 2\r\n", t.snapshotRequestSubject()))); err != nil {
+		return err
+	}
+	if _, err := conn.Write([]byte(fmt.Sprintf("SUB %s 3\r\n", t.inbox))); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" || line == "PING" {
+			if line == "PING" {
+				conn.Write([]byte("PONG\r\n"))
+			}
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 1 || !strings.EqualFold(fields[0], "MSG") {
+			continue // +OK, etc.
+		}
+		if len(fields) < 4 {
+			continue
+		}
+		subject := fields[1]
+		size, err := strconv.Atoi(fields[len(fields)-1])
+		if err != nil {
+			continue
+		}
+		payload := make([]byte, size+2)
+		if _, err := readFull(reader, payload); err != nil {
+			return err
+		}
+		t.handleMessage(subject, payload[:size])
+	}
+}
+
+func (t *NatsTransport) handleMessage(subject string, payload []byte) {
+	switch subject {
+	case t.subject:
+		var delta SyncDelta
+		if err := json.Unmarshal(payload, &delta); err != nil {
+			log.Printf("[NatsTransport] Bad delta payload: %v", err)
+			return
+		}
+		if t.handler != nil {
+			t.handler(delta)
+		}
+	case t.snapshotRequestSubject():
+		t.respondToSnapshotRequest(payload)
+	case t.inbox:
+		t.deliverSnapshotReply(payload)
+	}
+}
+
+func (t *NatsTransport) respondToSnapshotRequest(requestPayload []byte) {
+	t.mu.Lock()
+	provider := t.provider
+	t.mu.Unlock()
+	if provider == nil {
+		return
+	}
+
+	var req struct {
+		ReplySubject string `json:"replySubject"`
+	}
+	if err := json.Unmarshal(requestPayload, &req); err != nil || req.ReplySubject == "" {
+		return
+	}
+
+	payload, err := json.Marshal(provider())
+	if err != nil {
+		log.Printf("[NatsTransport] Failed to marshal snapshot reply: %v", err)
+		return
+	}
+	if err := t.publishRaw(req.ReplySubject, payload); err != nil {
+		log.Printf("[NatsTransport] Failed to publish snapshot reply: %v", err)
+	}
+}
+
+func (t *NatsTransport) deliverSnapshotReply(payload []byte) {
+	t.mu.Lock()
+	ch := t.pendingSnapshot
+	t.mu.Unlock()
+	if ch == nil {
+		return
+	}
+	var snapshot map[string]*SessionEntry
+	if err := json.Unmarshal(payload, &snapshot); err != nil {
+		return
+	}
+	select {
+	case ch <- snapshot:
+	default:
+	}
+}
+
+// RequestSnapshot implements SyncTransport.
+func (t *NatsTransport) RequestSnapshot() (map[string]*SessionEntry, error) {
+	ch := make(chan map[string]*SessionEntry, 1)
+	t.mu.Lock()
+	t.pendingSnapshot = ch
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		t.pendingSnapshot = nil
+		t.mu.Unlock()
+	}()
+
+	req, _ := json.Marshal(struct {
+		ReplySubject string `json:"replySubject"`
+	}{ReplySubject: t.inbox})
+	if err := t.publishRaw(t.snapshotRequestSubject(), req); err != nil {
+		return nil, err
+	}
+
+	select {
+	case snapshot := <-ch:
+		return snapshot, nil
+	case <-time.After(3 * time.Second):
+		return nil, nil
+	}
+}
+
+// ServeSnapshot implements SyncTransport.
+func (t *NatsTransport) ServeSnapshot(provider func() map[string]*SessionEntry) error {
+	t.mu.Lock()
+	t.provider = provider
+	t.mu.Unlock()
+	return nil
+}
+
+// Close implements SyncTransport.
+func (t *NatsTransport) Close() error {
+	close(t.closed)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.conn != nil {
+		return t.conn.Close()
+	}
+	return nil
+}
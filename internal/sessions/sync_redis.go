@@ -0,0 +1,325 @@
+package sessions
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RedisTransport is a SyncTransport backed by Redis pub/sub. It speaks a
+// minimal subset of RESP directly over net.Conn rather than pulling in a
+// full client library, matching the rest of this package's dependency-light
+// style. Deltas are published as JSON on a single channel; the
+// snapshot/resync handshake uses a request channel plus a
+// reply-subject-per-request pattern.
+type RedisTransport struct {
+	addr     string
+	channel  string
+	replyTag string
+
+	mu      sync.Mutex
+	pubConn net.Conn
+
+	handler  func(SyncDelta)
+	provider func() map[string]*SessionEntry
+
+	pendingSnapshot chan map[string]*SessionEntry
+
+	closed chan struct{}
+}
+
+// NewRedisTransport connects to a Redis server at addr (host:port) and
+// replicates deltas over channel. Each process gets a unique reply subject
+// for snapshot responses so concurrent resyncs don't cross-talk.
+func NewRedisTransport(addr, channel string) (*RedisTransport, error) {
+	t := &RedisTransport{
+		addr:     addr,
+		channel:  channel,
+		replyTag: uuid.NewString(),
+		closed:   make(chan struct{}),
+	}
+
+	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("redis transport: dial %s: %w", addr, err)
+	}
+	t.pubConn = conn
+
+	return t, nil
+}
+
+func (t *RedisTransport) snapshotRequestChannel() string { return t.channel + ":snapshot-request" }
+func (t *RedisTransport) snapshotReplyChannel() string {
+	return t.channel + ":snapshot-reply:" + t.replyTag
+}
+
+// Publish implements SyncTransport.
+func (t *RedisTransport) Publish(delta SyncDelta) error {
+	payload, err := json.Marshal(delta)
+	if err != nil {
+		return fmt.Errorf("redis transport: marshal delta: %w", err)
+	}
+	return t.publishRaw(t.channel, payload)
+}
+
+func (t *RedisTransport) publishRaw(channel string, payload []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return respWriteCommand(t.pubConn, "PUBLISH", channel, string(payload))
+}
+
+// Subscribe implements SyncTransport. It opens a dedicated connection for
+// the subscribe loop (RESP connections in subscribe mode can't also issue
+// PUBLISH) and reconnects with backoff if the connection drops.
+func (t *RedisTransport) Subscribe(handler func(SyncDelta)) error {
+	t.handler = handler
+	go t.subscribeLoop()
+	return nil
+}
+
+func (t *RedisTransport) subscribeLoop() {
+	backoff := 500 * time.Millisecond
+	const maxBackoff = 30 * time.Second
+
+	for {
+		select {
+		case <-t.closed:
+			return
+		default:
+		}
+
+		conn, err := net.DialTimeout("tcp", t.addr, 5*time.Second)
+		if err != nil {
+			log.Printf("[RedisTransport] Subscribe dial failed, retrying in %v: %v", backoff, err)
+			time.Sleep(backoff)
+			if backoff < maxBackoff {
+				backoff *= 2
+			}
+			continue
+		}
+		backoff = 500 * time.Millisecond
+
+		if err := t.runSubscription(conn); err != nil {
+			log.Printf("[RedisTransport] Subscription ended, reconnecting: %v", err)
+		}
+		conn.Close()
+	}
+}
+
+func (t *RedisTransport) runSubscription(conn net.Conn) error {
+	if err := respWriteCommand(conn, "SUBSCRIBE", t.channel, t.snapshotRequestChannel(), t.snapshotReplyChannel()); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(conn)
+	for {
+		reply, err := respReadReply(reader)
+		if err != nil {
+			return err
+		}
+		fields, ok := reply.([]interface{})
+		if !ok || len(fields) < 3 {
+			continue
+		}
+		kind, _ := fields[0].(string)
+		if kind != "message" {
+			continue
+		}
+		channel, _ := fields[1].(string)
+		payload, _ := fields[2].(string)
+		t.handleMessage(channel, []byte(payload))
+	}
+}
+
+func (t *RedisTransport) handleMessage(channel string, payload []byte) {
+	switch channel {
+	case t.channel:
+		var delta SyncDelta
+		if err := json.Unmarshal(payload, &delta); err != nil {
+			log.Printf("[RedisTransport] Bad delta payload: %v", err)
+			return
+		}
+		if t.handler != nil {
+			t.handler(delta)
+		}
+	case t.snapshotRequestChannel():
+		t.respondToSnapshotRequest(payload)
+	case t.snapshotReplyChannel():
+		t.deliverSnapshotReply(payload)
+	}
+}
+
+func (t *RedisTransport) respondToSnapshotRequest(requestPayload []byte) {
+	t.mu.Lock()
+	provider := t.provider
+	t.mu.Unlock()
+	if provider == nil {
+		return
+	}
+
+	var req struct {
+		ReplyChannel string `json:"replyChannel"`
+	}
+	if err := json.Unmarshal(requestPayload, &req); err != nil || req.ReplyChannel == "" {
+		return
+	}
+
+	payload, err := json.Marshal(provider())
+	if err != nil {
+		log.Printf("[RedisTransport] Failed to marshal snapshot reply: %v", err)
+		return
+	}
+	if err := t.publishRaw(req.ReplyChannel, payload); err != nil {
+		log.Printf("[RedisTransport] Failed to publish snapshot reply: %v", err)
+	}
+}
+
+func (t *RedisTransport) deliverSnapshotReply(payload []byte) {
+	t.mu.Lock()
+	ch := t.pendingSnapshot
+	t.mu.Unlock()
+	if ch == nil {
+		return
+	}
+	var snapshot map[string]*SessionEntry
+	if err := json.Unmarshal(payload, &snapshot); err != nil {
+		return
+	}
+	select {
+	case ch <- snapshot:
+	default:
+	}
+}
+
+// RequestSnapshot implements SyncTransport: it publishes a resync request
+// naming this transport's reply channel and waits briefly for an answer.
+func (t *RedisTransport) RequestSnapshot() (map[string]*SessionEntry, error) {
+	ch := make(chan map[string]*SessionEntry, 1)
+	t.mu.Lock()
+	t.pendingSnapshot = ch
+	t.mu.Unlock()
+	defer func() {
+		t.mu.Lock()
+		t.pendingSnapshot = nil
+		t.mu.Unlock()
+	}()
+
+	req, _ := json.Marshal(struct {
+		ReplyChannel string `json:"replyChannel"`
+	}{ReplyChannel: t.snapshotReplyChannel()})
+	if err := t.publishRaw(t.snapshotRequestChannel(), req); err != nil {
+		return nil, err
+	}
+
+	select {
+	case snapshot := <-ch:
+		return snapshot, nil
+	case <-time.After(3 * time.Second):
+		return nil, nil
+	}
+}
+
+// ServeSnapshot implements SyncTransport.
+func (t *RedisTransport) ServeSnapshot(provider func() map[string]*SessionEntry) error {
+	t.mu.Lock()
+	t.provider = provider
+	t.mu.Unlock()
+	return nil
+}
+
+// Close implements SyncTransport.
+func (t *RedisTransport) Close() error {
+	close(t.closed)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.pubConn != nil {
+		return t.pubConn.Close()
+	}
+	return nil
+}
+
+// respWriteCommand writes a RESP array-of-bulk-strings command.
+func respWriteCommand(conn net.Conn, args ...string) error {
+	buf := fmt.Sprintf("*%d\r\n", len(args))
+	for _, arg := range args {
+		buf += fmt.Sprintf("$%d\r\n%s\r\n", len(arg), arg)
+	}
+	_, err := conn.Write([]byte(buf))
+	return err
+}
+
+// respReadReply reads a single RESP value: a simple string, integer, bulk
+// string, or array (possibly nested), enough to decode pub/sub messages and
+// command replies.
+func respReadReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = trimCRLF(line)
+	if len(line) == 0 {
+		return respReadReply(r)
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, fmt.Errorf("redis error: %s", line[1:])
+	case ':':
+		n, _ := strconv.ParseInt(line[1:], 10, 64)
+		return n, nil
+	case '$':
+		n, _ := strconv.Atoi(line[1:])
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := readFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, _ := strconv.Atoi(line[1:])
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, n)
+		for i := 0; i < n; i++ {
+			item, err := respReadReply(r)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis transport: unexpected reply prefix %q", line[0])
+	}
+}
+
+func trimCRLF(s string) string {
+	for len(s) > 0 && (s[len(s)-1] == '\n' || s[len(s)-1] == '\r') {
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+func readFull(r *bufio.Reader, buf []byte) (int, error) {
+	total := 0
+	for total < len(buf) {
+		n, err := r.Read(buf[total:])
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
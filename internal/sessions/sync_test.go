@@ -0,0 +1,143 @@
+package sessions
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newTestSyncStore(t *testing.T) *Store {
+	t.Helper()
+	store := NewStoreWithBackend(NewFileBackend(&StoreConfig{
+		StorePath:     filepath.Join(t.TempDir(), "sessions.json"),
+		FlushInterval: time.Millisecond,
+	}))
+	t.Cleanup(store.Close)
+	return store
+}
+
+// waitForEntry polls store for key until want is true or the deadline
+// passes, so a test does not depend on exactly when a replicated delta
+// lands in store.
+func waitForEntry(t *testing.T, store *Store, key string, want func(*SessionEntry) bool) *SessionEntry {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		entry, err := store.GetEntry(key)
+		if err != nil {
+			t.Fatalf("GetEntry(%q) error = %v", key, err)
+		}
+		if want(entry) {
+			return entry
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("GetEntry(%q) never satisfied the expected condition", key)
+	return nil
+}
+
+func TestSyncServiceReplicatesWritesBetweenTwoReplicas(t *testing.T) {
+	bus := NewInMemoryBus()
+
+	storeA := newTestSyncStore(t)
+	syncA := NewSyncService(storeA, bus.NewTransport())
+	if err := syncA.Start(); err != nil {
+		t.Fatalf("syncA.Start() error = %v", err)
+	}
+	t.Cleanup(func() { syncA.Close() })
+
+	storeB := newTestSyncStore(t)
+	syncB := NewSyncService(storeB, bus.NewTransport())
+	if err := syncB.Start(); err != nil {
+		t.Fatalf("syncB.Start() error = %v", err)
+	}
+	t.Cleanup(func() { syncB.Close() })
+
+	if _, err := storeA.UpdateEntry("agent:main:webhook:1", func(*SessionEntry) (*SessionEntry, error) {
+		return &SessionEntry{SessionID: "sess_1"}, nil
+	}); err != nil {
+		t.Fatalf("storeA.UpdateEntry() error = %v", err)
+	}
+
+	entry := waitForEntry(t, storeB, "agent:main:webhook:1", func(e *SessionEntry) bool { return e != nil })
+	if entry.SessionID != "sess_1" {
+		t.Fatalf("storeB entry = %+v, want SessionID sess_1 replicated from storeA", entry)
+	}
+
+	if _, err := storeB.UpdateEntry("agent:main:webhook:2", func(*SessionEntry) (*SessionEntry, error) {
+		return &SessionEntry{SessionID: "sess_2"}, nil
+	}); err != nil {
+		t.Fatalf("storeB.UpdateEntry() error = %v", err)
+	}
+
+	entry = waitForEntry(t, storeA, "agent:main:webhook:2", func(e *SessionEntry) bool { return e != nil })
+	if entry.SessionID != "sess_2" {
+		t.Fatalf("storeA entry = %+v, want SessionID sess_2 replicated from storeB", entry)
+	}
+}
+
+// TestSyncServiceAppliesNewerUpdateFromQuietPeer reproduces the scenario a
+// plain-per-key "local vs remote" clock comparison gets wrong: a busy
+// replica's independent counter races far ahead on keys a quiet peer never
+// touches, so without reconciling the two counters (see bumpClock) the
+// quiet peer's later write to a shared key can look numerically "older"
+// and get dropped forever.
+func TestSyncServiceAppliesNewerUpdateFromQuietPeer(t *testing.T) {
+	bus := NewInMemoryBus()
+
+	busyStore := newTestSyncStore(t)
+	busySync := NewSyncService(busyStore, bus.NewTransport())
+	if err := busySync.Start(); err != nil {
+		t.Fatalf("busySync.Start() error = %v", err)
+	}
+	t.Cleanup(func() { busySync.Close() })
+
+	quietStore := newTestSyncStore(t)
+	quietSync := NewSyncService(quietStore, bus.NewTransport())
+	if err := quietSync.Start(); err != nil {
+		t.Fatalf("quietSync.Start() error = %v", err)
+	}
+	t.Cleanup(func() { quietSync.Close() })
+
+	// Race the busy replica's local clock far ahead with writes to keys the
+	// quiet replica never touches.
+	for i := 0; i < 20; i++ {
+		key := busyKey(i)
+		if _, err := busyStore.UpdateEntry(key, func(*SessionEntry) (*SessionEntry, error) {
+			return &SessionEntry{SessionID: "sess_busy"}, nil
+		}); err != nil {
+			t.Fatalf("busyStore.UpdateEntry(%q) error = %v", key, err)
+		}
+	}
+	waitForEntry(t, quietStore, busyKey(19), func(e *SessionEntry) bool { return e != nil })
+
+	// The busy replica now also writes the shared key, ahead of the quiet
+	// replica's upcoming write in wall-clock terms.
+	if _, err := busyStore.UpdateEntry("agent:main:webhook:shared", func(*SessionEntry) (*SessionEntry, error) {
+		return &SessionEntry{SessionID: "sess_from_busy"}, nil
+	}); err != nil {
+		t.Fatalf("busyStore.UpdateEntry(shared) error = %v", err)
+	}
+	waitForEntry(t, quietStore, "agent:main:webhook:shared", func(e *SessionEntry) bool {
+		return e != nil && e.SessionID == "sess_from_busy"
+	})
+
+	// The quiet replica, whose own counter never raced ahead, now publishes
+	// a genuinely newer update to the same shared key.
+	if _, err := quietStore.UpdateEntry("agent:main:webhook:shared", func(*SessionEntry) (*SessionEntry, error) {
+		return &SessionEntry{SessionID: "sess_from_quiet"}, nil
+	}); err != nil {
+		t.Fatalf("quietStore.UpdateEntry(shared) error = %v", err)
+	}
+
+	entry := waitForEntry(t, busyStore, "agent:main:webhook:shared", func(e *SessionEntry) bool {
+		return e != nil && e.SessionID == "sess_from_quiet"
+	})
+	if entry.SessionID != "sess_from_quiet" {
+		t.Fatalf("busyStore entry = %+v, want the quiet replica's newer write to win", entry)
+	}
+}
+
+func busyKey(i int) string {
+	return "agent:main:webhook:busy-" + string(rune('a'+i))
+}
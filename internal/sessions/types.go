@@ -36,6 +36,11 @@ type SessionEntry struct {
 	WebhookMessageID string `json:"webhookMessageId,omitempty"`
 	WebhookSessionID string `json:"webhookSessionId,omitempty"`
 
+	// ExpiresAt, if set, overrides Store's TTL/GroupTTL for this entry:
+	// Expire deletes it once ExpiresAt (unix millis) has passed, regardless
+	// of UpdatedAt.
+	ExpiresAt int64 `json:"expiresAt,omitempty"`
+
 	// Session state
 	SystemSent     bool `json:"systemSent,omitempty"`
 	AbortedLastRun bool `json:"abortedLastRun,omitempty"`
@@ -61,20 +66,70 @@ type DeliveryContext struct {
 
 // StoreConfig holds configuration for the session store
 type StoreConfig struct {
-	// StorePath is the path to the session store JSON file
+	// StorePath is the path to the session store JSON file (FileBackend only)
 	StorePath string
-	// CacheTTL is how long to cache the store in memory
+	// CacheTTL is how long to cache the store in memory (FileBackend only)
 	CacheTTL time.Duration
-	// LockTimeout is how long to wait for a lock
+	// LockTimeout is how long to wait for a lock (FileBackend's flock, and
+	// BoltBackend's bbolt.Open file lock)
 	LockTimeout time.Duration
+	// FlushInterval is how often FileBackend's batching writer goroutine
+	// coalesces pending UpdateEntry calls into one WAL append (see applyBatch).
+	// Defaults to 50ms. FileBackend only.
+	FlushInterval time.Duration
+	// MaxPendingWrites bounds FileBackend's pending-write queue; once full,
+	// UpdateEntry blocks until the writer catches up. Defaults to 256.
+	// FileBackend only.
+	MaxPendingWrites int
+	// CompactInterval is how often FileBackend folds its write-ahead log
+	// (see wal.go) into a fresh snapshot. Defaults to 5 minutes.
+	// FileBackend only.
+	CompactInterval time.Duration
+
+	// Driver selects the storage backend: StoreDriverFile (default),
+	// StoreDriverBolt, StoreDriverRedis, or StoreDriverEtcd.
+	Driver string
+
+	// BoltPath is the path to the bbolt database file, used when
+	// Driver == StoreDriverBolt.
+	BoltPath string
+
+	// RedisAddr is the Redis server address (host:port), used when
+	// Driver == StoreDriverRedis.
+	RedisAddr string
+	// RedisKeyPrefix namespaces session keys in Redis; entries are stored
+	// under RedisKeyPrefix+sessionKey. Defaults to "openclaw:session:".
+	RedisKeyPrefix string
+
+	// EtcdEndpoint is the etcd v3 JSON/gRPC-gateway base URL (e.g.
+	// "http://localhost:2379"), used when Driver == StoreDriverEtcd.
+	EtcdEndpoint string
+	// EtcdKeyPrefix namespaces session keys in etcd. Defaults to
+	// "/openclaw/session/".
+	EtcdKeyPrefix string
+
+	// TTL is how long a session entry may sit idle (UpdatedAt) before a
+	// background sweep (see NewStore) or a manual Store.Expire call deletes
+	// it. Zero disables expiry. Without this, the webhook:{id} keys minted
+	// per-message by ResolveSessionKey accumulate without bound.
+	TTL time.Duration
+	// GroupTTL, if non-zero, overrides TTL for group/channel sessions (see
+	// IsGroupSessionKey), which are worth keeping around longer than
+	// one-shot webhook sessions.
+	GroupTTL time.Duration
 }
 
-// DefaultStoreConfig returns the default store configuration
+// DefaultStoreConfig returns the default (file-backed) store configuration
 func DefaultStoreConfig(storePath string) *StoreConfig {
 	return &StoreConfig{
-		StorePath:   storePath,
-		CacheTTL:    45 * time.Second,
-		LockTimeout: 10 * time.Second,
+		StorePath:        storePath,
+		CacheTTL:         45 * time.Second,
+		LockTimeout:      10 * time.Second,
+		Driver:           StoreDriverFile,
+		TTL:              30 * 24 * time.Hour,
+		GroupTTL:         90 * 24 * time.Hour,
+		FlushInterval:    50 * time.Millisecond,
+		MaxPendingWrites: 256,
 	}
 }
 
@@ -134,6 +189,9 @@ func MergeSessionEntry(existing *SessionEntry, patch *SessionEntry) *SessionEntr
 	if patch.WebhookSessionID != "" {
 		result.WebhookSessionID = patch.WebhookSessionID
 	}
+	if patch.ExpiresAt > 0 {
+		result.ExpiresAt = patch.ExpiresAt
+	}
 
 	// Merge boolean flags (override if explicitly set)
 	if patch.SystemSent {
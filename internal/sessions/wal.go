@@ -0,0 +1,109 @@
+package sessions
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+)
+
+// walRecord is one line of FileBackend's write-ahead log: the fully merged
+// entry for a single session key, exactly as it would appear in the
+// snapshot after a compaction. Replaying every record in file order and
+// overwriting by Key reproduces the same map a full rewrite would have
+// produced, just without paying that rewrite's cost on every UpdateEntry.
+type walRecord struct {
+	Key   string        `json:"key"`
+	Entry *SessionEntry `json:"entry"`
+}
+
+// walPath returns the WAL file alongside config.StorePath.
+func (s *FileBackend) walPath() string {
+	return s.config.StorePath + ".wal"
+}
+
+// walAppend appends records to the WAL in one os.OpenFile call, under the
+// caller's flock (see applyBatch). It never touches the snapshot file, so
+// it's cheap relative to saveUnlocked's marshal-temp-rename cycle - that's
+// the whole point of batching UpdateEntry through the WAL instead of a full
+// rewrite every FlushInterval tick.
+func (s *FileBackend) walAppend(records []walRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	f, err := os.OpenFile(s.walPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open wal: %w", err)
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	for _, rec := range records {
+		data, err := json.Marshal(rec)
+		if err != nil {
+			return fmt.Errorf("failed to marshal wal record: %w", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return fmt.Errorf("failed to write wal record: %w", err)
+		}
+		if err := w.WriteByte('\n'); err != nil {
+			return fmt.Errorf("failed to write wal record: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write wal record: %w", err)
+	}
+	// A caller that's seen UpdateEntry return success needs these records to
+	// survive more than a process restart - fsync so they're durable across
+	// a power loss/disk-level crash too, not just sitting in the page cache.
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("failed to fsync wal: %w", err)
+	}
+	return nil
+}
+
+// walReplay reads every record appended to the WAL since the last
+// compaction and applies it onto store (later records for the same key
+// win), so readers see writes that haven't been folded into the snapshot
+// yet. A missing WAL file means nothing has been appended since the last
+// compaction, which is the common case right after startup.
+func (s *FileBackend) walReplay(store map[string]*SessionEntry) error {
+	f, err := os.Open(s.walPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to open wal: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		var rec walRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			// A half-written trailing line means a crash mid-append; it
+			// never reached the caller as a successful write, so stop
+			// replaying rather than fail the whole load.
+			log.Printf("[SessionStore] Skipping truncated wal record: %v", err)
+			break
+		}
+		store[rec.Key] = rec.Entry
+	}
+	return scanner.Err()
+}
+
+// walTruncate removes the WAL file once its records have been folded into a
+// freshly written snapshot (see saveUnlocked), so it doesn't get replayed a
+// second time on top of a snapshot that already contains it.
+func (s *FileBackend) walTruncate() error {
+	if err := os.Remove(s.walPath()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to truncate wal: %w", err)
+	}
+	return nil
+}
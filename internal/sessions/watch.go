@@ -0,0 +1,122 @@
+package sessions
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// SessionChangeOp identifies the kind of mutation described by a
+// SessionChangeEvent.
+type SessionChangeOp string
+
+const (
+	// SessionChangeOpPut means Key now holds Entry (created or updated).
+	SessionChangeOpPut SessionChangeOp = "put"
+	// SessionChangeOpDelete means Key was removed; Entry is nil.
+	SessionChangeOpDelete SessionChangeOp = "delete"
+)
+
+// SessionChangeEvent describes a single session mutation observed by a
+// Backend's Watch, whether made by this process (a local Save/UpdateEntry)
+// or a peer replica writing to the same backend.
+type SessionChangeEvent struct {
+	Op    SessionChangeOp
+	Key   string
+	Entry *SessionEntry
+}
+
+// Watcher is an optional Backend capability: backends that can observe
+// mutations out-of-band (fsnotify for FileBackend, keyspace notifications
+// for RedisBackend, clientv3-style watch for EtcdBackend) implement it so
+// Store.Watch can stream SessionChangeEvents instead of forcing callers to
+// poll Load/GetEntry.
+type Watcher interface {
+	// Watch streams session change events until ctx is cancelled, at which
+	// point the returned channel is closed.
+	Watch(ctx context.Context) (<-chan SessionChangeEvent, error)
+}
+
+// Watch streams session change events from the backend, if it supports
+// watching (see Watcher). This lets downstream components - the webhook
+// router and outbound dispatcher, for instance - invalidate their in-memory
+// view of LastChannel/LastTo/DeliveryContext without polling Load, and
+// react to RecordInboundMeta/UpdateLastRoute calls made by a peer process
+// sharing the same backend.
+func (s *Store) Watch(ctx context.Context) (<-chan SessionChangeEvent, error) {
+	watcher, ok := s.backend.(Watcher)
+	if !ok {
+		return nil, fmt.Errorf("sessions: %T does not support Watch", s.backend)
+	}
+	return watcher.Watch(ctx)
+}
+
+// changeHub fans out SessionChangeEvents to however many Watch callers are
+// currently subscribed. It's embedded by every Backend that implements
+// Watcher; the backend's watch loop calls publish, Store.Watch callers get
+// their own channel from subscribe.
+type changeHub struct {
+	mu   sync.Mutex
+	subs map[chan SessionChangeEvent]struct{}
+}
+
+// subscribe registers a new subscriber channel that is unregistered and
+// closed when ctx is done.
+func (h *changeHub) subscribe(ctx context.Context) <-chan SessionChangeEvent {
+	ch := make(chan SessionChangeEvent, 16)
+
+	h.mu.Lock()
+	if h.subs == nil {
+		h.subs = make(map[chan SessionChangeEvent]struct{})
+	}
+	h.subs[ch] = struct{}{}
+	h.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		h.mu.Lock()
+		delete(h.subs, ch)
+		h.mu.Unlock()
+		close(ch)
+	}()
+
+	return ch
+}
+
+// publish fans event out to every subscriber. A subscriber too slow to keep
+// up has the event dropped rather than blocking the backend's watch loop.
+func (h *changeHub) publish(event SessionChangeEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// diffSessionEntries compares a previous and current snapshot of the full
+// session map and returns the Put/Delete events needed to bring a watcher
+// from prev up to next.
+func diffSessionEntries(prev, next map[string]*SessionEntry) []SessionChangeEvent {
+	var events []SessionChangeEvent
+	for key, entry := range next {
+		if old, ok := prev[key]; !ok || !sessionEntryEqual(old, entry) {
+			events = append(events, SessionChangeEvent{Op: SessionChangeOpPut, Key: key, Entry: entry})
+		}
+	}
+	for key := range prev {
+		if _, ok := next[key]; !ok {
+			events = append(events, SessionChangeEvent{Op: SessionChangeOpDelete, Key: key})
+		}
+	}
+	return events
+}
+
+func sessionEntryEqual(a, b *SessionEntry) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
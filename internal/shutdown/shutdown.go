@@ -0,0 +1,73 @@
+// Package shutdown coordinates a bridge process's exit: closers are
+// registered with a priority and a timeout, then Coordinator.Shutdown runs
+// them in priority order, logging how long each one took, and forcibly
+// exits the process if a closer hangs past its timeout rather than leaving
+// the daemon stuck forever on a half-open socket.
+package shutdown
+
+import (
+	"context"
+	"log"
+	"os"
+	"sort"
+	"time"
+)
+
+// Closer is one registered shutdown step: Close is called with at most
+// Timeout to return before Shutdown gives up on it.
+type Closer struct {
+	Name     string
+	Priority int
+	Timeout  time.Duration
+	Close    func(ctx context.Context) error
+}
+
+// Coordinator collects Closers and runs them in order on Shutdown. The zero
+// value is not usable; use New.
+type Coordinator struct {
+	closers []Closer
+}
+
+// New returns an empty Coordinator.
+func New() *Coordinator {
+	return &Coordinator{}
+}
+
+// Register adds a closer to run during Shutdown. Closers run in ascending
+// priority order (lowest first), so e.g. the webhook and OpenClaw clients
+// (priority 1) stop taking new work before the session store underneath
+// them (priority 10) is closed.
+func (c *Coordinator) Register(name string, priority int, timeout time.Duration, close func(ctx context.Context) error) {
+	c.closers = append(c.closers, Closer{Name: name, Priority: priority, Timeout: timeout, Close: close})
+}
+
+// Shutdown runs every registered closer in priority order, logging each
+// one's duration and any error. A closer that doesn't return within its
+// Timeout is logged and the process is forcibly exited (os.Exit(1)) -
+// Shutdown does not return in that case, so callers should register
+// closers in the order they'd want partial progress logged, not rely on
+// cleanup after Shutdown for the timed-out closer.
+func (c *Coordinator) Shutdown(ctx context.Context) {
+	ordered := append([]Closer(nil), c.closers...)
+	sort.SliceStable(ordered, func(i, j int) bool { return ordered[i].Priority < ordered[j].Priority })
+
+	for _, cl := range ordered {
+		start := time.Now()
+		done := make(chan error, 1)
+		go func(cl Closer) {
+			done <- cl.Close(ctx)
+		}(cl)
+
+		select {
+		case err := <-done:
+			if err != nil {
+				log.Printf("[Shutdown] %s failed after %s: %v", cl.Name, time.Since(start), err)
+			} else {
+				log.Printf("[Shutdown] %s closed in %s", cl.Name, time.Since(start))
+			}
+		case <-time.After(cl.Timeout):
+			log.Printf("[Shutdown] %s did not close within %s, forcing exit", cl.Name, cl.Timeout)
+			os.Exit(1)
+		}
+	}
+}
@@ -11,6 +11,8 @@ import (
 	"time"
 
 	"github.com/gorilla/websocket"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/backoff"
+	"github.com/sternelee/openclaw-webhook-bridge/internal/metrics"
 )
 
 // MessageHandler is called when a message is received from the webhook
@@ -21,11 +23,59 @@ type MessageHandler func(data []byte) error
 // The data is raw JSON bytes from OpenClaw
 type ResponseHandler func() ([]byte, error)
 
+// KeepaliveConfig controls the WebSocket ping/pong keepalive that detects a
+// silently-dropped connection (NAT rebind, half-open socket) long before the
+// OS would otherwise notice.
+type KeepaliveConfig struct {
+	// PingInterval is how often a ping is sent on an idle connection.
+	// Defaults to 30s.
+	PingInterval time.Duration
+	// PongWait is how long ReadMessage may block without a pong or any other
+	// frame before the connection is considered dead. Should be comfortably
+	// larger than PingInterval (defaults to 2.5x it, i.e. 75s).
+	PongWait time.Duration
+	// WriteTimeout bounds how long a ping (or any other write) may block.
+	// Defaults to 10s.
+	WriteTimeout time.Duration
+}
+
+// DefaultKeepaliveConfig returns the keepalive defaults used when NewClient
+// is passed a nil KeepaliveConfig.
+func DefaultKeepaliveConfig() KeepaliveConfig {
+	return KeepaliveConfig{
+		PingInterval: 30 * time.Second,
+		PongWait:     75 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+}
+
+// BackoffConfig controls the reconnect delay between connectionLoop
+// attempts (see internal/backoff for the decorrelated-jitter algorithm) and
+// how long a connection must stay up before that delay resets to Base,
+// instead of resetting on any successful-but-fleeting dial.
+type BackoffConfig struct {
+	Base            time.Duration
+	Cap             time.Duration
+	StableThreshold time.Duration
+}
+
+// DefaultBackoffConfig returns the backoff defaults used when NewClient is
+// passed a nil BackoffConfig.
+func DefaultBackoffConfig() BackoffConfig {
+	return BackoffConfig{
+		Base:            2 * time.Second,
+		Cap:             30 * time.Second,
+		StableThreshold: 30 * time.Second,
+	}
+}
+
 // Client is a WebSocket webhook client
 type Client struct {
 	url       string
 	uid       string // Unique ID for this bridge
 	handler   MessageHandler
+	keepalive KeepaliveConfig
+	dialer    *websocket.Dialer
 	conn      *websocket.Conn
 	connMu    sync.RWMutex
 	connected atomic.Bool
@@ -33,18 +83,53 @@ type Client struct {
 	cancel    context.CancelFunc
 	wg        sync.WaitGroup
 
+	reconnectDelay  *backoff.Decorrelated
+	stableThreshold time.Duration
+
+	// writeMu serializes every write to conn (gorilla/websocket forbids
+	// concurrent writes): ordinary Send traffic and the keepalive ping
+	// writer both go through writeLocked.
+	writeMu sync.Mutex
+
 	// Connection state notification
 	connCond *sync.Cond
 }
 
-// NewClient creates a new webhook client
-func NewClient(url string, handler MessageHandler, uid string) *Client {
-	return &Client{
-		url:      url,
-		uid:      uid,
-		handler:  handler,
-		connCond: sync.NewCond(&sync.Mutex{}),
+// NewClient creates a new webhook client. url may be ws:// or, with
+// tlsConfig set, wss://. keepalive, tlsConfig and backoffConfig are all
+// optional - pass nil for any of them to use their respective defaults.
+func NewClient(url string, handler MessageHandler, uid string, tlsConfig *TLSConfig, keepalive *KeepaliveConfig, backoffConfig *BackoffConfig) (*Client, error) {
+	cfg := DefaultKeepaliveConfig()
+	if keepalive != nil {
+		cfg = *keepalive
+	}
+
+	bcfg := DefaultBackoffConfig()
+	if backoffConfig != nil {
+		bcfg = *backoffConfig
+	}
+
+	dialer, err := buildDialer(tlsConfig)
+	if err != nil {
+		return nil, err
 	}
+
+	return &Client{
+		url:             url,
+		uid:             uid,
+		handler:         handler,
+		keepalive:       cfg,
+		dialer:          dialer,
+		reconnectDelay:  backoff.NewDecorrelated(backoff.Config{Base: bcfg.Base, Cap: bcfg.Cap}),
+		stableThreshold: bcfg.StableThreshold,
+		connCond:        sync.NewCond(&sync.Mutex{}),
+	}, nil
+}
+
+// IsConnected reports whether the client currently has a live webhook
+// connection.
+func (c *Client) IsConnected() bool {
+	return c.connected.Load()
 }
 
 // Connect establishes a WebSocket connection to the webhook server
@@ -118,13 +203,14 @@ func (c *Client) Close() error {
 	return nil
 }
 
-// connectionLoop maintains a persistent connection with auto-reconnect
+// connectionLoop maintains a persistent connection with auto-reconnect. The
+// delay between attempts follows decorrelated jitter (see internal/backoff)
+// rather than plain doubling, so many bridges reconnecting at once don't
+// all retry in lockstep; connectAndRead's deferred cleanup is what resets
+// it, and only once the connection has proven stable.
 func (c *Client) connectionLoop() {
 	defer c.wg.Done()
 
-	reconnectDelay := 2 * time.Second
-	maxReconnectDelay := 30 * time.Second
-
 	for {
 		select {
 		case <-c.ctx.Done():
@@ -135,21 +221,16 @@ func (c *Client) connectionLoop() {
 
 		if err := c.connectAndRead(); err != nil {
 			log.Printf("[Webhook] Connection error: %v", err)
-
-			// Exponential backoff for reconnection
-			if reconnectDelay < maxReconnectDelay {
-				reconnectDelay *= 2
-			}
-		} else {
-			// Successful connection, reset delay
-			reconnectDelay = 2 * time.Second
+			metrics.WebhookReconnectErrorsTotal.Inc()
 		}
 
+		delay := c.reconnectDelay.Next()
+
 		// Wait before reconnecting (or exit if context cancelled)
 		select {
 		case <-c.ctx.Done():
 			return
-		case <-time.After(reconnectDelay):
+		case <-time.After(delay):
 			log.Printf("[Webhook] Reconnecting...")
 		}
 	}
@@ -170,7 +251,7 @@ func (c *Client) connectAndRead() error {
 
 	log.Printf("[Webhook] Connecting to %s (UID: %s)", wsURL, c.uid)
 
-	conn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	conn, _, err := c.dialer.Dial(wsURL, nil)
 	if err != nil {
 		return fmt.Errorf("failed to dial: %w", err)
 	}
@@ -179,11 +260,27 @@ func (c *Client) connectAndRead() error {
 	c.conn = conn
 	c.connMu.Unlock()
 
+	c.installKeepaliveHandlers(conn)
+	conn.SetReadDeadline(time.Now().Add(c.keepalive.PongWait))
+
+	pingDone := make(chan struct{})
+	go c.pingLoop(conn, pingDone)
+	defer close(pingDone)
+
+	connectedAt := time.Now()
 	c.connected.Store(true)
 	c.connCond.Broadcast() // Wake up any waiters
 	defer func() {
 		c.connected.Store(false)
 		c.connCond.Broadcast() // Wake up any waiters on disconnect
+
+		// Only treat the connection as proven stable - and so reset the
+		// backoff - if it survived at least stableThreshold; a connection
+		// that dies immediately after handshake shouldn't reset a backoff
+		// that's trying to ride out a flapping server.
+		if time.Since(connectedAt) >= c.stableThreshold {
+			c.reconnectDelay.Reset()
+		}
 	}()
 
 	// Read messages
@@ -204,6 +301,63 @@ func (c *Client) connectAndRead() error {
 	}
 }
 
+// installKeepaliveHandlers wires up the pong handler (extends the read
+// deadline whenever the peer acks one of our pings) and the ping handler
+// (replies to a peer-initiated ping and also extends the read deadline).
+func (c *Client) installKeepaliveHandlers(conn *websocket.Conn) {
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(c.keepalive.PongWait))
+	})
+	conn.SetPingHandler(func(data string) error {
+		conn.SetReadDeadline(time.Now().Add(c.keepalive.PongWait))
+		err := c.writeControl(conn, websocket.PongMessage, []byte(data))
+		if err == websocket.ErrCloseSent {
+			return nil
+		}
+		return err
+	})
+}
+
+// pingLoop sends a PingMessage every PingInterval until done is closed. A
+// failed ping means the connection is already dead; conn.Close nudges
+// ReadMessage in connectAndRead to return promptly instead of waiting out
+// the read deadline.
+func (c *Client) pingLoop(conn *websocket.Conn, done <-chan struct{}) {
+	ticker := time.NewTicker(c.keepalive.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.writeControl(conn, websocket.PingMessage, nil); err != nil {
+				conn.Close()
+				return
+			}
+		}
+	}
+}
+
+// writeControl sends a control frame, serialized against every other write
+// on conn via writeMu (gorilla/websocket forbids concurrent writes).
+func (c *Client) writeControl(conn *websocket.Conn, messageType int, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return conn.WriteControl(messageType, data, time.Now().Add(c.keepalive.WriteTimeout))
+}
+
+// writeMessage sends a data frame, serialized against every other write on
+// conn via writeMu (gorilla/websocket forbids concurrent writes).
+func (c *Client) writeMessage(conn *websocket.Conn, messageType int, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	conn.SetWriteDeadline(time.Now().Add(c.keepalive.WriteTimeout))
+	return conn.WriteMessage(messageType, data)
+}
+
 // Send forwards raw JSON data to the webhook (from OpenClaw)
 func (c *Client) Send(data []byte) error {
 	// Early return if not connected to avoid acquiring lock unnecessarily
@@ -221,7 +375,7 @@ func (c *Client) Send(data []byte) error {
 
 	// Don't log message content for privacy
 
-	if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+	if err := c.writeMessage(conn, websocket.TextMessage, data); err != nil {
 		return fmt.Errorf("failed to send: %w", err)
 	}
 
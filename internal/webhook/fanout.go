@@ -0,0 +1,267 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sternelee/openclaw-webhook-bridge/internal/backoff"
+)
+
+// TargetAuth configures the authentication a TargetConfig's HTTP POST
+// carries, mirroring the single bearer-or-basic choice most downstream
+// webhook sinks expect.
+type TargetAuth struct {
+	// BearerToken, when set, is sent as "Authorization: Bearer <token>".
+	BearerToken string
+	// BasicUser/BasicPass, when BasicUser is set, are sent as HTTP Basic
+	// auth instead. Ignored if BearerToken is also set.
+	BasicUser string
+	BasicPass string
+}
+
+// RetryPolicy bounds how a FanoutTarget retries a failed delivery before
+// giving up on that event, using the same decorrelated-jitter backoff as
+// the reconnect loops in Client.
+type RetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+// DefaultRetryPolicy is used by targets that don't specify their own.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxRetries: 3, BaseDelay: time.Second, MaxDelay: 30 * time.Second}
+}
+
+// TargetConfig describes one downstream HTTP sink in a fan-out: OpenClaw
+// events matching EventTypes (or every event, if empty) are POSTed to URL
+// as JSON.
+type TargetConfig struct {
+	Name       string
+	URL        string
+	Auth       TargetAuth
+	EventTypes []string // empty matches every event type
+	Retry      RetryPolicy
+}
+
+// TargetStatus is a point-in-time snapshot of one fan-out target, for the
+// "status" CLI output.
+type TargetStatus struct {
+	Name         string
+	LastAttempt  time.Time
+	LastSuccess  time.Time
+	LastError    string
+	FailureCount int
+}
+
+// FanoutTarget delivers events matching its config to one downstream HTTP
+// endpoint, retrying per Retry before giving up on an individual event.
+type FanoutTarget struct {
+	config     TargetConfig
+	httpClient *http.Client
+
+	mu     sync.Mutex
+	status TargetStatus
+}
+
+// NewFanoutTarget builds a FanoutTarget from config, defaulting its retry
+// policy to DefaultRetryPolicy when unset.
+func NewFanoutTarget(config TargetConfig) *FanoutTarget {
+	if config.Retry == (RetryPolicy{}) {
+		config.Retry = DefaultRetryPolicy()
+	}
+	return &FanoutTarget{
+		config:     config,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		status:     TargetStatus{Name: config.Name},
+	}
+}
+
+// Matches reports whether eventType should be delivered to this target -
+// every event type when EventTypes is empty, an exact match otherwise.
+func (t *FanoutTarget) Matches(eventType string) bool {
+	if len(t.config.EventTypes) == 0 {
+		return true
+	}
+	for _, want := range t.config.EventTypes {
+		if want == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Deliver POSTs data to the target, retrying with decorrelated-jitter
+// backoff per t.config.Retry until it succeeds or MaxRetries is exhausted.
+// ctx cancellation aborts the retry loop early.
+func (t *FanoutTarget) Deliver(ctx context.Context, data []byte) error {
+	delay := backoff.NewDecorrelated(backoff.Config{Base: t.config.Retry.BaseDelay, Cap: t.config.Retry.MaxDelay})
+
+	var lastErr error
+	for attempt := 0; attempt <= t.config.Retry.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay.Next()):
+			}
+		}
+
+		t.recordAttempt()
+		if err := t.post(ctx, data); err != nil {
+			lastErr = err
+			t.recordError(err)
+			continue
+		}
+		t.recordSuccess()
+		return nil
+	}
+	return fmt.Errorf("fanout target %s: giving up after %d attempts: %w", t.config.Name, t.config.Retry.MaxRetries+1, lastErr)
+}
+
+func (t *FanoutTarget) post(ctx context.Context, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.config.URL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	switch {
+	case t.config.Auth.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+t.config.Auth.BearerToken)
+	case t.config.Auth.BasicUser != "":
+		req.SetBasicAuth(t.config.Auth.BasicUser, t.config.Auth.BasicPass)
+	}
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (t *FanoutTarget) recordAttempt() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.status.LastAttempt = time.Now()
+}
+
+func (t *FanoutTarget) recordSuccess() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.status.LastSuccess = time.Now()
+	t.status.LastError = ""
+	t.status.FailureCount = 0
+}
+
+func (t *FanoutTarget) recordError(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.status.LastError = err.Error()
+	t.status.FailureCount++
+}
+
+// Status returns a snapshot of this target's delivery state.
+func (t *FanoutTarget) Status() TargetStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.status
+}
+
+// eventEnvelope is the minimal shape FanoutSender needs to read out of an
+// event's JSON to apply EventTypes filtering; everything else in data is
+// forwarded to targets verbatim.
+type eventEnvelope struct {
+	Type string `json:"type"`
+}
+
+// FanoutSender implements emitter.Sender, delivering each event to primary
+// (the bridge's existing WebSocket webhook.Client) and, in parallel, to
+// every additional TargetConfig whose EventTypes filter matches. It lets a
+// bridge.json with a "webhooks" array fan one OpenClaw agent's events out
+// to several downstream services (e.g. a chat UI plus a logging sink)
+// alongside its normal inbound/outbound WebSocket channel.
+type FanoutSender struct {
+	primaryMu sync.RWMutex
+	primary   Sender
+	targets   []*FanoutTarget
+}
+
+// Sender is the subset of webhook.Client (or any other primary event sink)
+// FanoutSender wraps, matching internal/bridge/emitter.Sender.
+type Sender interface {
+	Send(data []byte) error
+}
+
+// NewFanoutSender wraps primary (typically the bridge's webhook.Client)
+// with additional HTTP fan-out targets. primary may be nil if there is no
+// WebSocket channel and the bridge should rely solely on targets.
+func NewFanoutSender(primary Sender, targets []*FanoutTarget) *FanoutSender {
+	return &FanoutSender{primary: primary, targets: targets}
+}
+
+// UpdatePrimary atomically swaps the primary sink - e.g. when a config
+// reload reconnects the bridge's webhook.Client to a new URL - so Send
+// picks up the new primary on its very next call instead of keeping the
+// old (possibly now-closed) one.
+func (f *FanoutSender) UpdatePrimary(primary Sender) {
+	f.primaryMu.Lock()
+	f.primary = primary
+	f.primaryMu.Unlock()
+}
+
+// currentPrimary returns the active primary sink, safe to call
+// concurrently with UpdatePrimary.
+func (f *FanoutSender) currentPrimary() Sender {
+	f.primaryMu.RLock()
+	defer f.primaryMu.RUnlock()
+	return f.primary
+}
+
+// Send implements emitter.Sender: it forwards to primary (if set) and
+// returns as soon as that call returns, since emitter.BufferedEmitter.run
+// calls Send synchronously and only advances its WAL once Send returns -
+// that's the channel the rest of the bridge depends on for delivery
+// guarantees. Fan-out targets are dispatched to in the background and never
+// waited on here, so a struggling logging sink retrying per its
+// RetryPolicy can't stall the primary chat channel's own retries; their
+// failures are logged and tracked via each target's own Status, not
+// surfaced through this return value.
+func (f *FanoutSender) Send(data []byte) error {
+	var eventType string
+	var env eventEnvelope
+	if json.Unmarshal(data, &env) == nil {
+		eventType = env.Type
+	}
+
+	for _, target := range f.targets {
+		if !target.Matches(eventType) {
+			continue
+		}
+		target := target
+		go target.Deliver(context.Background(), data)
+	}
+
+	if primary := f.currentPrimary(); primary != nil {
+		return primary.Send(data)
+	}
+	return nil
+}
+
+// Status returns a snapshot of every fan-out target's delivery state, for
+// the "status" CLI output.
+func (f *FanoutSender) Status() []TargetStatus {
+	statuses := make([]TargetStatus, len(f.targets))
+	for i, t := range f.targets {
+		statuses[i] = t.Status()
+	}
+	return statuses
+}
@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeSender struct {
+	sent atomic.Int32
+	err  error
+}
+
+func (f *fakeSender) Send(data []byte) error {
+	f.sent.Add(1)
+	return f.err
+}
+
+func TestFanoutSenderDoesNotBlockOnSlowTarget(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer slow.Close()
+	defer close(release)
+
+	target := NewFanoutTarget(TargetConfig{
+		Name:  "slow",
+		URL:   slow.URL,
+		Retry: RetryPolicy{MaxRetries: 0, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond},
+	})
+
+	primary := &fakeSender{}
+	sender := NewFanoutSender(primary, []*FanoutTarget{target})
+
+	done := make(chan error, 1)
+	go func() { done <- sender.Send([]byte(`{"type":"x"}`)) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Send() error = %v, want nil", err)
+		}
+	case <-time.After(200 * time.Millisecond):
+		t.Fatal("Send() blocked on a target still waiting on its HTTP response")
+	}
+	if primary.sent.Load() != 1 {
+		t.Fatalf("primary.sent = %d, want 1", primary.sent.Load())
+	}
+}
+
+func TestFanoutSenderUpdatePrimary(t *testing.T) {
+	first := &fakeSender{}
+	second := &fakeSender{}
+	sender := NewFanoutSender(first, nil)
+
+	if err := sender.Send([]byte(`{}`)); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	sender.UpdatePrimary(second)
+	if err := sender.Send([]byte(`{}`)); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if first.sent.Load() != 1 {
+		t.Fatalf("first.sent = %d, want 1", first.sent.Load())
+	}
+	if second.sent.Load() != 1 {
+		t.Fatalf("second.sent = %d, want 1", second.sent.Load())
+	}
+}
+
+func TestFanoutTargetMatches(t *testing.T) {
+	all := NewFanoutTarget(TargetConfig{Name: "all"})
+	if !all.Matches("anything") {
+		t.Fatal("target with no EventTypes should match every event type")
+	}
+
+	filtered := NewFanoutTarget(TargetConfig{Name: "filtered", EventTypes: []string{"message"}})
+	if !filtered.Matches("message") {
+		t.Fatal("filtered target should match a listed event type")
+	}
+	if filtered.Matches("other") {
+		t.Fatal("filtered target should not match an unlisted event type")
+	}
+}